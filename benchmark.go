@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Standard benchmark units, shown before any custom b.ReportMetric units
+const (
+	unitNsPerOp     = "ns/op"
+	unitBytesPerOp  = "B/op"
+	unitAllocsPerOp = "allocs/op"
+	unitMBPerSec    = "MB/s"
+)
+
+// standardBenchmarkUnits is the column order of the standard units
+var standardBenchmarkUnits = []string{unitNsPerOp, unitBytesPerOp, unitAllocsPerOp, unitMBPerSec}
+
+// benchmarkLineRegex matches a benchmark result line:
+// "BenchmarkConcat-8   229614   437.0 ns/op   64 B/op   9 allocs/op"
+var benchmarkLineRegex = regexp.MustCompile(`^(Benchmark\S*)\s+(\d+)\s+(.+)$`)
+
+// benchmarkFailRegex matches the line the testing package prints for a failed benchmark
+var benchmarkFailRegex = regexp.MustCompile(`^\s*--- FAIL: (Benchmark\S*)`)
+
+// BenchmarkSample is one result line of a benchmark; -count=N gives N samples
+type BenchmarkSample struct {
+	Iterations int64
+	Values     map[string]float64 // Unit -> value, e.g. "ns/op" -> 437
+}
+
+// BenchmarkResult is every sample of one benchmark
+type BenchmarkResult struct {
+	Name    string // As printed, including the -GOMAXPROCS suffix (e.g. "BenchmarkConcat-8")
+	Samples []BenchmarkSample
+}
+
+// Value returns the median of the benchmark's samples for unit
+// Returns false if no sample reported the unit
+func (b BenchmarkResult) Value(unit string) (float64, bool) {
+	values := b.Values(unit)
+	if len(values) == 0 {
+		return 0, false
+	}
+	return median(values), true
+}
+
+// Values returns the value of unit from every sample that reported it
+func (b BenchmarkResult) Values(unit string) []float64 {
+	var values []float64
+	for _, sample := range b.Samples {
+		if value, ok := sample.Values[unit]; ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Iterations returns the iteration count of the benchmark's last sample
+func (b BenchmarkResult) Iterations() int64 {
+	if len(b.Samples) == 0 {
+		return 0
+	}
+	return b.Samples[len(b.Samples)-1].Iterations
+}
+
+// PackageBenchmarkResult is the outcome of running a package's benchmarks
+type PackageBenchmarkResult struct {
+	PackagePath string
+	Status      string // "PASS", "FAIL", "BUILD FAIL", "RUNNING", "CANCELLED"
+	Benchmarks  []BenchmarkResult
+	Running     string   // Benchmark in progress while streaming
+	Failed      []string // Benchmarks that reported --- FAIL
+	Goos        string
+	Goarch      string
+	CPU         string
+	Count       int // Samples per benchmark requested (-count)
+	Duration    time.Duration
+	Started     time.Time
+	FullOutput  string
+	BuildErrors []BuildDiagnostic
+}
+
+// Units returns every unit reported by the benchmarks: the standard ones first,
+// then custom units (b.ReportMetric) alphabetically
+func (r *PackageBenchmarkResult) Units() []string {
+	seen := make(map[string]bool)
+	var custom []string
+	for _, bench := range r.Benchmarks {
+		for _, sample := range bench.Samples {
+			for unit := range sample.Values {
+				if !seen[unit] {
+					seen[unit] = true
+					if !containsString(standardBenchmarkUnits, unit) {
+						custom = append(custom, unit)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(custom)
+
+	var units []string
+	for _, unit := range standardBenchmarkUnits {
+		if seen[unit] {
+			units = append(units, unit)
+		}
+	}
+	return append(units, custom...)
+}
+
+// benchmarkParser incrementally builds a PackageBenchmarkResult from go test -json events
+// A result line is split over several output events (the name is printed before
+// the benchmark runs), so output is joined back into lines before parsing
+type benchmarkParser struct {
+	result      *PackageBenchmarkResult
+	output      strings.Builder             // Plain text of every output event, as go test would print it
+	pending     string                      // Output after the last newline
+	index       map[string]int              // Benchmark name -> index in result.Benchmarks
+	buildOutput map[string]*strings.Builder // Build output per import path being built
+	failedBuild string                      // Import path whose build failure failed the package, if any
+}
+
+// newBenchmarkParser creates a parser that accumulates events into result
+func newBenchmarkParser(result *PackageBenchmarkResult) *benchmarkParser {
+	return &benchmarkParser{
+		result:      result,
+		index:       make(map[string]int),
+		buildOutput: make(map[string]*strings.Builder),
+	}
+}
+
+// Consume applies a single test event to the result being built
+func (p *benchmarkParser) Consume(event TestEvent) {
+	result := p.result
+
+	switch event.Action {
+	case "build-output":
+		builder, ok := p.buildOutput[event.ImportPath]
+		if !ok {
+			builder = &strings.Builder{}
+			p.buildOutput[event.ImportPath] = builder
+		}
+		builder.WriteString(event.Output)
+		p.output.WriteString(event.Output)
+
+	case "run":
+		if event.Test != "" {
+			result.Running = event.Test
+		}
+
+	case "output":
+		p.output.WriteString(event.Output)
+		p.pending += event.Output
+		for {
+			newline := strings.IndexByte(p.pending, '\n')
+			if newline < 0 {
+				break
+			}
+			p.parseLine(p.pending[:newline])
+			p.pending = p.pending[newline+1:]
+		}
+
+	case "pass", "fail", "skip":
+		if event.Test == "" {
+			result.Duration = time.Duration(event.Elapsed * float64(time.Second))
+			result.Running = ""
+			if event.FailedBuild != "" {
+				p.failedBuild = event.FailedBuild
+				if builder, ok := p.buildOutput[event.FailedBuild]; ok {
+					result.BuildErrors = parseBuildDiagnostics(builder.String())
+				}
+			}
+		}
+	}
+}
+
+// parseLine records a benchmark result, failure or header line
+func (p *benchmarkParser) parseLine(line string) {
+	result := p.result
+	line = strings.TrimRight(line, "\r")
+
+	switch {
+	case strings.HasPrefix(line, "goos: "):
+		result.Goos = strings.TrimPrefix(line, "goos: ")
+	case strings.HasPrefix(line, "goarch: "):
+		result.Goarch = strings.TrimPrefix(line, "goarch: ")
+	case strings.HasPrefix(line, "cpu: "):
+		result.CPU = strings.TrimPrefix(line, "cpu: ")
+	}
+
+	if matches := benchmarkFailRegex.FindStringSubmatch(line); matches != nil {
+		if !containsString(result.Failed, matches[1]) {
+			result.Failed = append(result.Failed, matches[1])
+		}
+		return
+	}
+
+	name, sample, ok := parseBenchmarkLine(line)
+	if !ok {
+		return
+	}
+	idx, exists := p.index[name]
+	if !exists {
+		result.Benchmarks = append(result.Benchmarks, BenchmarkResult{Name: name})
+		idx = len(result.Benchmarks) - 1
+		p.index[name] = idx
+	}
+	result.Benchmarks[idx].Samples = append(result.Benchmarks[idx].Samples, sample)
+}
+
+// parseBenchmarkLine parses a benchmark result line into its name and sample
+// Values come in "value unit" pairs after the iteration count
+func parseBenchmarkLine(line string) (string, BenchmarkSample, bool) {
+	matches := benchmarkLineRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return "", BenchmarkSample{}, false
+	}
+	iterations, err := strconv.ParseInt(matches[2], 10, 64)
+	if err != nil {
+		return "", BenchmarkSample{}, false
+	}
+
+	fields := strings.Fields(matches[3])
+	if len(fields) < 2 || len(fields)%2 != 0 {
+		return "", BenchmarkSample{}, false
+	}
+	sample := BenchmarkSample{Iterations: iterations, Values: make(map[string]float64)}
+	for i := 0; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return "", BenchmarkSample{}, false
+		}
+		sample.Values[fields[i+1]] = value
+	}
+	return matches[1], sample, true
+}
+
+// median returns the median of values, which must not be empty
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// RunBenchmarks runs the benchmarks of a package with -benchmem; tests are skipped
+// mode selects the build tags, as for tests; opts.count sets the samples per benchmark
+// onEvent, if non-nil, is called for every event as it is read from go test
+// Cancelling ctx kills the go test process group; the partial result is returned
+// with status "CANCELLED"
+func RunBenchmarks(ctx context.Context, packageDir string, packageName string, mode testMode, opts testRunOptions, onEvent func(TestEvent)) (*PackageBenchmarkResult, error) {
+	LogInfo("Running benchmarks",
+		"package", packageName,
+		"directory", packageDir,
+		"mode", mode,
+		"count", opts.count,
+		"profile", opts.profile.String(),
+	)
+
+	result := &PackageBenchmarkResult{
+		PackagePath: packageName,
+		Status:      "RUNNING",
+		Count:       Max(opts.count, 1),
+		Started:     time.Now(),
+	}
+
+	if mode == testModeAll {
+		mode = tagsMode(opts.allTags)
+	}
+	// -run=^$ keeps tests (and their output) out of the benchmark run
+	opts.runPattern = "^$"
+	args := []string{"test", "-json", "-bench=.", "-benchmem", opts.countArg()}
+	modeArgs, _ := testModeArgs(mode, opts.profile.Tags)
+	args = append(args, modeArgs...)
+	args = append(args, opts.args()...)
+	args = append(args, ".")
+
+	cmd, stdout, stderr, err := startGoTest(ctx, packageDir, args, opts.profile.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := newBenchmarkParser(result)
+	readTestOutput(stdout, func(line string) {
+		if event, ok := decodeTestEvent(line); ok {
+			parser.Consume(event)
+			if onEvent != nil {
+				onEvent(event)
+			}
+		}
+	})
+
+	err = waitGoTest(cmd)
+	result.FullOutput = parser.output.String() + stderr.String()
+	result.Running = ""
+	resolveBuildDiagnostics(result.BuildErrors, packageDir)
+
+	switch {
+	case ctx.Err() != nil:
+		result.Status = "CANCELLED"
+	case parser.failedBuild != "":
+		result.Status = buildFailStatus
+	case err != nil, len(result.Failed) > 0:
+		result.Status = "FAIL"
+	default:
+		result.Status = "PASS"
+	}
+
+	LogInfo("Benchmarks complete",
+		"package", packageName,
+		"status", result.Status,
+		"benchmarks", len(result.Benchmarks),
+		"failed", len(result.Failed),
+		"duration", result.Duration.String(),
+	)
+	return result, nil
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// benchmarkComparison is one benchmark of a baseline and a candidate run
+type benchmarkComparison struct {
+	Name        string
+	Base, Cand  sampleSummary // N is 0 when the run didn't have the benchmark
+	Delta       float64       // Relative change of the median, e.g. 0.12 for +12%
+	P           float64       // p-value of the Mann-Whitney U test
+	Significant bool          // P is below benchmarkAlpha
+}
+
+// compareBenchmarks compares the samples of unit in two runs, benchmark by benchmark
+// Benchmarks are listed in the candidate's order, followed by those only in the baseline
+func compareBenchmarks(base, cand *PackageBenchmarkResult, unit string) []benchmarkComparison {
+	baseByName := make(map[string]BenchmarkResult, len(base.Benchmarks))
+	for _, bench := range base.Benchmarks {
+		baseByName[bench.Name] = bench
+	}
+
+	var comparisons []benchmarkComparison
+	seen := make(map[string]bool)
+	add := func(name string, baseValues, candValues []float64) {
+		if len(baseValues) == 0 && len(candValues) == 0 {
+			return
+		}
+		comparison := benchmarkComparison{
+			Name: name,
+			Base: summarizeSamples(baseValues),
+			Cand: summarizeSamples(candValues),
+			P:    1,
+		}
+		if len(baseValues) > 0 && len(candValues) > 0 {
+			comparison.P = mannWhitneyUTest(baseValues, candValues)
+			comparison.Significant = comparison.P < benchmarkAlpha
+			if comparison.Base.Median != 0 {
+				comparison.Delta = (comparison.Cand.Median - comparison.Base.Median) / math.Abs(comparison.Base.Median)
+			}
+		}
+		comparisons = append(comparisons, comparison)
+	}
+
+	for _, bench := range cand.Benchmarks {
+		seen[bench.Name] = true
+		add(bench.Name, baseByName[bench.Name].Values(unit), bench.Values(unit))
+	}
+	for _, bench := range base.Benchmarks {
+		if !seen[bench.Name] {
+			add(bench.Name, bench.Values(unit), nil)
+		}
+	}
+	return comparisons
+}
+
+// higherIsBetter reports whether a larger value of unit is an improvement
+// Throughput units (MB/s, and any other rate) are; times, bytes and counts per op aren't
+func higherIsBetter(unit string) bool {
+	return strings.HasSuffix(unit, "/s")
+}
+
+// BenchmarkSnapshot is a saved benchmark run to compare later runs against
+type BenchmarkSnapshot struct {
+	Saved  time.Time
+	Result *PackageBenchmarkResult
+}
+
+// benchmarkSnapshotDir returns the directory snapshots of a package are kept in,
+// next to the config file
+func benchmarkSnapshotDir(configPath string, packageDir string) string {
+	return filepath.Join(filepath.Dir(configPath), "benchmarks", dirKey(packageDir))
+}
+
+// dirKey turns a package directory into a file name unique to it, from its absolute path
+func dirKey(packageDir string) string {
+	absDir, err := filepath.Abs(packageDir)
+	if err != nil {
+		absDir = packageDir
+	}
+	// Separators and the drive letter's colon on Windows aren't valid in a file name
+	return strings.Trim(strings.NewReplacer("/", "_", ":", "_").Replace(filepath.ToSlash(absDir)), "_")
+}
+
+// saveBenchmarkSnapshot writes a benchmark run to the package's snapshot directory
+// Returns the path of the snapshot
+func saveBenchmarkSnapshot(dir string, result *PackageBenchmarkResult) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	// The samples are what later comparisons need; the raw output is left out
+	stored := *result
+	stored.FullOutput = ""
+	snapshot := BenchmarkSnapshot{Saved: time.Now(), Result: &stored}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// Snapshots saved within the same second get a numbered suffix rather than
+	// replacing each other
+	name := snapshot.Saved.Format("20060102-150405")
+	path := filepath.Join(dir, name+".json")
+	for n := 2; ; n++ {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = file.Write(data)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			return path, err
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.json", name, n))
+	}
+}
+
+// loadBenchmarkSnapshots reads the snapshots of a package, newest first
+// Unreadable snapshots are skipped
+func loadBenchmarkSnapshots(dir string) []BenchmarkSnapshot {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var snapshots []BenchmarkSnapshot
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			LogWarn("Failed to read benchmark snapshot", "path", path, "error", err)
+			continue
+		}
+		var snapshot BenchmarkSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil || snapshot.Result == nil {
+			LogWarn("Failed to parse benchmark snapshot", "path", path, "error", err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Saved.After(snapshots[j].Saved)
+	})
+	return snapshots
+}
+
+// benchmarkBaseline is a run the latest benchmarks can be compared against
+type benchmarkBaseline struct {
+	label  string
+	result *PackageBenchmarkResult
+}
+
+// BenchmarkCompareState holds the state for the benchmark comparison view
+type BenchmarkCompareState struct {
+	packageName string
+	baselines   []benchmarkBaseline // Previous run first, then snapshots newest first
+	baseline    int                 // Index of the baseline compared against
+	column      int                 // Index into the candidate's units of the unit compared
+	selected    int
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+// FormatBenchmarkComparison renders a benchstat-style comparison of the
+// candidate run against the selected baseline for one unit
+// Returns the content and the line of the selected row
+func FormatBenchmarkComparison(cand *PackageBenchmarkResult, theme Theme, state BenchmarkCompareState, width int) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
+	headerStyle := lipgloss.NewStyle().Foreground(theme.NormalFg).Bold(true)
+	betterStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00"))
+	worseStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(normalStyle.Render("Benchmark comparison: "+cand.PackagePath) + "\n")
+	if len(state.baselines) == 0 {
+		output.WriteString("\n" + normalStyle.Render("Nothing to compare against yet.") + "\n")
+		output.WriteString(helpStyle.Render("Run the benchmarks again (r) or save this run as a snapshot (S) in the benchmark view.") + "\n")
+		return output.String(), 0
+	}
+
+	base := state.baselines[Clamp(state.baseline, 0, len(state.baselines)-1)]
+	output.WriteString(normalStyle.Render("Baseline:  ") + metricStyle.Render(base.label) +
+		helpStyle.Render(fmt.Sprintf("  (%d of %d, n/p to switch)", state.baseline+1, len(state.baselines))) + "\n")
+	output.WriteString(normalStyle.Render("Candidate: ") + metricStyle.Render("latest run") +
+		helpStyle.Render(fmt.Sprintf("  %s", cand.Started.Format("2006-01-02 15:04:05"))) + "\n")
+
+	units := cand.Units()
+	if len(units) == 0 {
+		output.WriteString("\n" + normalStyle.Render("The latest run has no benchmark results.") + "\n")
+		return output.String(), 0
+	}
+	unit := units[Clamp(state.column, 0, len(units)-1)]
+	output.WriteString(normalStyle.Render("Unit: "))
+	for _, u := range units {
+		if u == unit {
+			output.WriteString(selectedStyle.Render(" "+u+" ") + " ")
+		} else {
+			output.WriteString(helpStyle.Render(" "+u+" ") + " ")
+		}
+	}
+	output.WriteString(helpStyle.Render(" (←→ to switch)") + "\n\n")
+
+	comparisons := compareBenchmarks(base.result, cand, unit)
+	nameWidth := len("benchmark")
+	for _, c := range comparisons {
+		nameWidth = Max(nameWidth, len(c.Name))
+	}
+	nameWidth = Min(nameWidth, 48)
+	const valueWidth = 20
+
+	header := fmt.Sprintf("  %-*s %*s %*s %10s  %s", nameWidth, "benchmark", valueWidth, "baseline", valueWidth, "candidate", "delta", "")
+	output.WriteString(headerStyle.Render(header) + "\n")
+	output.WriteString(separatorStyle.Render(strings.Repeat("─", Min(Max(width, 20), nameWidth+2*valueWidth+40))) + "\n")
+
+	cursorLine := 0
+	var baseMedians, candMedians []float64
+	for i, c := range comparisons {
+		name := c.Name
+		if len(name) > nameWidth {
+			name = "…" + name[len(name)-nameWidth+1:]
+		}
+		row := fmt.Sprintf("%-*s", nameWidth, name)
+		if i == state.selected {
+			cursorLine = strings.Count(output.String(), "\n")
+			output.WriteString(selectedStyle.Render(testCursorMarker + row))
+		} else {
+			output.WriteString(normalStyle.Render("  " + row))
+		}
+		output.WriteString(" " + normalStyle.Render(fmt.Sprintf("%*s", valueWidth, formatSummary(c.Base, unit))))
+		output.WriteString(" " + normalStyle.Render(fmt.Sprintf("%*s", valueWidth, formatSummary(c.Cand, unit))))
+
+		switch {
+		case c.Base.N == 0 || c.Cand.N == 0:
+			output.WriteString(" " + helpStyle.Render(fmt.Sprintf("%10s", "")))
+		case !c.Significant:
+			output.WriteString(" " + helpStyle.Render(fmt.Sprintf("%10s", "~")))
+		default:
+			delta := fmt.Sprintf("%10s", fmt.Sprintf("%+.2f%%", c.Delta*100))
+			if (c.Delta > 0) == higherIsBetter(unit) {
+				output.WriteString(" " + betterStyle.Render(delta))
+			} else {
+				output.WriteString(" " + worseStyle.Render(delta))
+			}
+		}
+		if c.Base.N > 0 && c.Cand.N > 0 {
+			output.WriteString(helpStyle.Render(fmt.Sprintf("  (p=%.3f n=%d+%d)", c.P, c.Base.N, c.Cand.N)))
+			if c.Base.Median > 0 && c.Cand.Median > 0 {
+				baseMedians = append(baseMedians, c.Base.Median)
+				candMedians = append(candMedians, c.Cand.Median)
+			}
+		}
+		output.WriteString("\n")
+	}
+
+	// Geometric mean of the benchmarks in both runs, as benchstat reports it
+	if len(baseMedians) > 1 {
+		baseMean, candMean := geomean(baseMedians), geomean(candMedians)
+		output.WriteString(separatorStyle.Render(strings.Repeat("─", Min(Max(width, 20), nameWidth+2*valueWidth+40))) + "\n")
+		output.WriteString(normalStyle.Render(fmt.Sprintf("  %-*s %*s %*s %10s", nameWidth, "geomean", valueWidth,
+			formatBenchmarkValue(baseMean, unit), valueWidth, formatBenchmarkValue(candMean, unit),
+			fmt.Sprintf("%+.2f%%", (candMean-baseMean)/baseMean*100))) + "\n")
+	}
+
+	output.WriteString("\n" + helpStyle.Render(fmt.Sprintf("Medians ± %.0f%% confidence interval; deltas with p ≥ %.2f (Mann-Whitney U) are shown as ~", benchmarkConfidence*100, benchmarkAlpha)) + "\n")
+	if base.result.CPU != cand.CPU && base.result.CPU != "" && cand.CPU != "" {
+		output.WriteString(worseStyle.Render(fmt.Sprintf("The runs were on different CPUs (%s vs %s)", base.result.CPU, cand.CPU)) + "\n")
+	}
+
+	return output.String(), cursorLine
+}
+
+// formatSummary formats a median with its confidence interval, e.g. "437.0ns ± 2%"
+func formatSummary(summary sampleSummary, unit string) string {
+	if summary.N == 0 {
+		return "-"
+	}
+	if !summary.HasCI {
+		return formatBenchmarkValue(summary.Median, unit) + " ± ∞"
+	}
+	return fmt.Sprintf("%s ± %.0f%%", formatBenchmarkValue(summary.Median, unit), summary.CIPercent())
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Statistics for comparing benchmark runs, in the spirit of benchstat: medians
+// with a distribution-free confidence interval, and a Mann-Whitney U test to
+// tell a real change from noise
+
+const (
+	benchmarkConfidence = 0.95 // Confidence level of the interval around each median
+	benchmarkAlpha      = 0.05 // Significance level a delta must reach to be reported
+)
+
+// sampleSummary summarizes the samples of one benchmark for one unit
+type sampleSummary struct {
+	Median float64
+	Lo, Hi float64 // Confidence interval of the median, valid if HasCI
+	HasCI  bool    // False when there are too few samples for the confidence level
+	N      int
+}
+
+// summarizeSamples returns the median of values and its confidence interval
+func summarizeSamples(values []float64) sampleSummary {
+	if len(values) == 0 {
+		return sampleSummary{}
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	summary := sampleSummary{Median: median(sorted), N: len(sorted)}
+	summary.Lo, summary.Hi, summary.HasCI = medianCI(sorted, benchmarkConfidence)
+	return summary
+}
+
+// CIPercent returns the half-width of the confidence interval as a percentage of the median
+func (s sampleSummary) CIPercent() float64 {
+	if s.Median == 0 {
+		return 0
+	}
+	return math.Max(s.Hi-s.Median, s.Median-s.Lo) / math.Abs(s.Median) * 100
+}
+
+// medianCI returns a confidence interval for the median of sorted samples from
+// order statistics: [x(k), x(n-k+1)] covers the median with probability
+// 1 - 2·P(X < k) for X ~ Binomial(n, 1/2), whatever the distribution
+// Returns false if even [min, max] doesn't reach the confidence level
+func medianCI(sorted []float64, confidence float64) (float64, float64, bool) {
+	n := len(sorted)
+	k := 0
+	for next := 1; next <= n/2; next++ {
+		if 1-2*binomialCDF(next-1, n) < confidence {
+			break
+		}
+		k = next
+	}
+	if k == 0 {
+		return 0, 0, false
+	}
+	return sorted[k-1], sorted[n-k], true
+}
+
+// binomialCDF returns P(X <= k) for X ~ Binomial(n, 1/2)
+func binomialCDF(k, n int) float64 {
+	var p float64
+	for i := 0; i <= k && i <= n; i++ {
+		p += math.Exp(logChoose(n, i) - float64(n)*math.Ln2)
+	}
+	return math.Min(p, 1)
+}
+
+// logChoose returns the natural log of n choose k
+func logChoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// mannWhitneyExactLimit is the largest combined sample size tested with the
+// exact distribution of U; larger samples use the normal approximation
+const mannWhitneyExactLimit = 50
+
+// mannWhitneyUTest returns the two-sided p-value of the Mann-Whitney U test that
+// a and b come from the same distribution
+// Small samples without ties use the exact distribution of U, others the normal
+// approximation with a tie correction
+func mannWhitneyUTest(a, b []float64) float64 {
+	n1, n2 := len(a), len(b)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	// Rank the pooled samples, averaging the ranks of ties
+	type value struct {
+		v     float64
+		fromA bool
+	}
+	pooled := make([]value, 0, n1+n2)
+	for _, v := range a {
+		pooled = append(pooled, value{v, true})
+	}
+	for _, v := range b {
+		pooled = append(pooled, value{v, false})
+	}
+	sort.Slice(pooled, func(i, j int) bool { return pooled[i].v < pooled[j].v })
+
+	var rankSumA, tieCorrection float64
+	hasTies := false
+	for i := 0; i < len(pooled); {
+		j := i
+		for j < len(pooled) && pooled[j].v == pooled[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // Average of ranks i+1..j
+		for k := i; k < j; k++ {
+			if pooled[k].fromA {
+				rankSumA += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			hasTies = true
+			tieCorrection += t*t*t - t
+		}
+		i = j
+	}
+	u := rankSumA - float64(n1*(n1+1))/2
+
+	if !hasTies && n1+n2 <= mannWhitneyExactLimit {
+		return mannWhitneyExactP(u, n1, n2)
+	}
+
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance) // With continuity correction
+	if z < 0 {
+		return 1
+	}
+	return math.Min(math.Erfc(z/math.Sqrt2), 1)
+}
+
+// mannWhitneyExactP returns the two-sided p-value of U from its exact
+// distribution under the null hypothesis, counting the orderings of n1+n2
+// distinct values that give each U
+func mannWhitneyExactP(u float64, n1, n2 int) float64 {
+	// counts[i][j][x]: orderings of i values of a and j of b with U = x
+	// The largest value is either from a (beating all j values of b) or from b
+	counts := make([][][]float64, n1+1)
+	for i := range counts {
+		counts[i] = make([][]float64, n2+1)
+		for j := range counts[i] {
+			counts[i][j] = make([]float64, i*j+1)
+			if i == 0 || j == 0 {
+				counts[i][j][0] = 1
+				continue
+			}
+			for x := range counts[i][j] {
+				if x-j >= 0 && x-j < len(counts[i-1][j]) {
+					counts[i][j][x] += counts[i-1][j][x-j]
+				}
+				if x < len(counts[i][j-1]) {
+					counts[i][j][x] += counts[i][j-1][x]
+				}
+			}
+		}
+	}
+
+	dist := counts[n1][n2]
+	var total, lower, upper float64
+	for x, c := range dist {
+		total += c
+		if float64(x) <= u {
+			lower += c
+		}
+		if float64(x) >= u {
+			upper += c
+		}
+	}
+	return math.Min(2*math.Min(lower, upper)/total, 1)
+}
+
+// geomean returns the geometric mean of positive values
+func geomean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var logSum float64
+	for _, v := range values {
+		logSum += math.Log(v)
+	}
+	return math.Exp(logSum / float64(len(values)))
+}
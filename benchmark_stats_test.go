@@ -0,0 +1,147 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// sequence returns the values from, from+step, ... n values in all
+func sequence(from, step float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = from + step*float64(i)
+	}
+	return values
+}
+
+func TestMannWhitneyUTest(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    []float64
+		want    float64 // Expected p-value, if tolerance > 0
+		tol     float64
+		wantMax float64 // Upper bound on the p-value, if > 0
+		wantMin float64 // Lower bound on the p-value, if > 0
+	}{
+		{
+			name: "exact, separated samples of 3",
+			a:    []float64{1, 2, 3},
+			b:    []float64{4, 5, 6},
+			want: 2.0 / 20, // One of the 20 orderings has U = 0, doubled for two sides
+			tol:  1e-12,
+		},
+		{
+			name: "exact, separated samples of 5",
+			a:    []float64{10, 11, 12, 13, 14},
+			b:    []float64{1, 2, 3, 4, 5},
+			want: 2.0 / 252,
+			tol:  1e-12,
+		},
+		{
+			name: "exact, interleaved samples",
+			a:    []float64{1, 3, 5},
+			b:    []float64{2, 4, 6},
+			want: 14.0 / 20, // U = 3; 7 of the 20 orderings have U <= 3
+			tol:  1e-12,
+		},
+		{
+			name: "normal approximation with ties",
+			a:    []float64{1, 1, 2, 2},
+			b:    []float64{3, 3, 4, 4},
+			want: 0.026518721959430752,
+			tol:  1e-9,
+		},
+		{
+			name:    "normal approximation beyond the exact limit",
+			a:       sequence(0, 1, 26),
+			b:       sequence(26, 1, 26),
+			wantMax: 1e-6,
+		},
+		{
+			name:    "normal approximation of interleaved samples",
+			a:       sequence(0, 2, 30),
+			b:       sequence(1, 2, 30),
+			wantMin: 0.5,
+		},
+		{
+			name: "all values equal",
+			a:    []float64{7, 7, 7},
+			b:    []float64{7, 7, 7},
+			want: 1,
+			tol:  1e-12,
+		},
+		{
+			name: "empty sample",
+			a:    nil,
+			b:    []float64{1, 2},
+			want: 1,
+			tol:  1e-12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := mannWhitneyUTest(tt.a, tt.b)
+			if tt.tol > 0 && math.Abs(p-tt.want) > tt.tol {
+				t.Errorf("p = %v, want %v", p, tt.want)
+			}
+			if tt.wantMax > 0 && p > tt.wantMax {
+				t.Errorf("p = %v, want at most %v", p, tt.wantMax)
+			}
+			if tt.wantMin > 0 && p < tt.wantMin {
+				t.Errorf("p = %v, want at least %v", p, tt.wantMin)
+			}
+			// The test is symmetric in its samples
+			if q := mannWhitneyUTest(tt.b, tt.a); math.Abs(p-q) > 1e-12 {
+				t.Errorf("p = %v with the samples swapped, want %v", q, p)
+			}
+		})
+	}
+}
+
+func TestMedianCI(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		wantLo float64
+		wantHi float64
+		wantOK bool
+	}{
+		{name: "too few samples", sorted: sequence(1, 1, 5)},
+		{name: "six samples use min and max", sorted: sequence(1, 1, 6), wantLo: 1, wantHi: 6, wantOK: true},
+		{name: "ten samples", sorted: sequence(1, 1, 10), wantLo: 2, wantHi: 9, wantOK: true},
+		{name: "twenty samples", sorted: sequence(1, 1, 20), wantLo: 6, wantHi: 15, wantOK: true},
+		{name: "no samples", sorted: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi, ok := medianCI(tt.sorted, benchmarkConfidence)
+			if ok != tt.wantOK || lo != tt.wantLo || hi != tt.wantHi {
+				t.Errorf("medianCI() = %v, %v, %v, want %v, %v, %v", lo, hi, ok, tt.wantLo, tt.wantHi, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSummarizeSamples(t *testing.T) {
+	summary := summarizeSamples([]float64{106, 100, 104, 98, 102, 96})
+	if summary.N != 6 || summary.Median != 101 {
+		t.Errorf("N = %d, Median = %v, want 6, 101", summary.N, summary.Median)
+	}
+	if !summary.HasCI || summary.Lo != 96 || summary.Hi != 106 {
+		t.Errorf("CI = [%v, %v] (%v), want [96, 106]", summary.Lo, summary.Hi, summary.HasCI)
+	}
+	if got, want := summary.CIPercent(), 5.0/101*100; math.Abs(got-want) > 1e-9 {
+		t.Errorf("CIPercent() = %v, want %v", got, want)
+	}
+}
+
+func TestGeomean(t *testing.T) {
+	if got := geomean([]float64{1, 4, 16}); math.Abs(got-4) > 1e-12 {
+		t.Errorf("geomean() = %v, want 4", got)
+	}
+	if got := geomean(nil); got != 0 {
+		t.Errorf("geomean(nil) = %v, want 0", got)
+	}
+}
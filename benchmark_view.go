@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// benchmarkSort is the order of the rows of the benchmark table
+type benchmarkSort int
+
+const (
+	benchmarkSortRun        benchmarkSort = iota // Order the benchmarks ran in
+	benchmarkSortAscending                       // Smallest value of the selected column first
+	benchmarkSortDescending                      // Largest value of the selected column first
+)
+
+const (
+	benchmarkColumnWidth = 12
+	benchmarkMaxBarWidth = 30
+)
+
+// BenchmarkViewState holds the state for the benchmark view
+type BenchmarkViewState struct {
+	packageName string
+	selected    int           // Row under the cursor
+	column      int           // Index into the result's units of the column sorted on and drawn as bars
+	order       benchmarkSort // Row order
+	notice      string        // Outcome of the last action, e.g. a saved snapshot
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+func newBenchmarkViewState(packageName string, returnTo appScreen) BenchmarkViewState {
+	return BenchmarkViewState{
+		packageName: packageName,
+		returnTo:    returnTo,
+	}
+}
+
+// sortedBenchmarks returns the benchmarks in the order of the view
+// Benchmarks that didn't report the column's unit go last
+func sortedBenchmarks(benchmarks []BenchmarkResult, unit string, order benchmarkSort) []BenchmarkResult {
+	sorted := make([]BenchmarkResult, len(benchmarks))
+	copy(sorted, benchmarks)
+	if order == benchmarkSortRun {
+		return sorted
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, aOK := sorted[i].Value(unit)
+		b, bOK := sorted[j].Value(unit)
+		if aOK != bOK {
+			return aOK
+		}
+		if order == benchmarkSortDescending {
+			return a > b
+		}
+		return a < b
+	})
+	return sorted
+}
+
+// FormatBenchmarks renders the benchmarks of a package as a table with one
+// column per unit, and bars comparing the benchmarks on the selected column
+// Returns the content and the line of the selected row
+func FormatBenchmarks(result *PackageBenchmarkResult, theme Theme, state BenchmarkViewState, width int) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	headerStyle := lipgloss.NewStyle().Foreground(theme.NormalFg).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(normalStyle.Render("Benchmarks: "+result.PackagePath) + "  " + styledStatus(result.Status, theme) + "\n")
+	if result.Goos != "" {
+		output.WriteString(helpStyle.Render(fmt.Sprintf("%s/%s  %s", result.Goos, result.Goarch, result.CPU)) + "\n")
+	}
+	switch {
+	case result.Status == "RUNNING":
+		running := "starting"
+		if result.Running != "" {
+			running = result.Running
+		}
+		output.WriteString(normalStyle.Render("Running: ") + metricStyle.Render(running) +
+			normalStyle.Render(fmt.Sprintf(" (%s elapsed)", time.Since(result.Started).Round(time.Second))) + "\n")
+	case result.Duration > 0:
+		output.WriteString(normalStyle.Render("Elapsed Time: ") + metricStyle.Render(formatDuration(result.Duration)) + "\n")
+	}
+	output.WriteString("\n")
+
+	if len(result.BuildErrors) > 0 {
+		output.WriteString(failStyle.Render(fmt.Sprintf("The test binary failed to build (%d error(s)):", len(result.BuildErrors))) + "\n")
+		for _, diagnostic := range result.BuildErrors {
+			output.WriteString(metricStyle.Render("  "+shortLocation(diagnostic.Location())) + normalStyle.Render("  "+diagnostic.Message) + "\n")
+		}
+		return output.String(), 0
+	}
+
+	units := result.Units()
+	if len(result.Benchmarks) == 0 || len(units) == 0 {
+		switch result.Status {
+		case "RUNNING":
+			output.WriteString(normalStyle.Render("Waiting for the first benchmark to finish...") + "\n")
+		case "FAIL":
+			output.WriteString(failStyle.Render("The benchmark run failed. Output:") + "\n")
+			output.WriteString(normalStyle.Render(lastLines(result.FullOutput, 30)) + "\n")
+		default:
+			output.WriteString(normalStyle.Render("No benchmarks found in this package.") + "\n")
+		}
+		return output.String(), 0
+	}
+
+	column := Clamp(state.column, 0, len(units)-1)
+	unit := units[column]
+	benchmarks := sortedBenchmarks(result.Benchmarks, unit, state.order)
+
+	nameWidth := len("benchmark")
+	for _, bench := range benchmarks {
+		nameWidth = Max(nameWidth, len(bench.Name))
+	}
+	nameWidth = Min(nameWidth, 48)
+	barWidth := Clamp(width-nameWidth-3-(len(units)+1)*(benchmarkColumnWidth+1), 0, benchmarkMaxBarWidth)
+
+	// Header: the sorted column shows its direction
+	header := fmt.Sprintf("  %-*s %*s", nameWidth, "benchmark", benchmarkColumnWidth, "iterations")
+	output.WriteString(headerStyle.Render(header))
+	for i, u := range units {
+		label := u
+		if i == column {
+			switch state.order {
+			case benchmarkSortAscending:
+				label += " ▲"
+			case benchmarkSortDescending:
+				label += " ▼"
+			default:
+				label += " ●"
+			}
+			output.WriteString(" " + selectedStyle.Render(fmt.Sprintf("%*s", benchmarkColumnWidth, label)))
+		} else {
+			output.WriteString(" " + headerStyle.Render(fmt.Sprintf("%*s", benchmarkColumnWidth, label)))
+		}
+	}
+	output.WriteString("\n" + separatorStyle.Render(strings.Repeat("─", Max(width, 20))) + "\n")
+
+	// Bars are relative to the largest value of the column within the package
+	var maxValue float64
+	for _, bench := range benchmarks {
+		if value, ok := bench.Value(unit); ok && value > maxValue {
+			maxValue = value
+		}
+	}
+
+	cursorLine := 0
+	for i, bench := range benchmarks {
+		name := bench.Name
+		if len(name) > nameWidth {
+			name = "…" + name[len(name)-nameWidth+1:]
+		}
+		row := fmt.Sprintf("%-*s", nameWidth, name)
+		if i == state.selected {
+			cursorLine = strings.Count(output.String(), "\n")
+			output.WriteString(selectedStyle.Render(testCursorMarker + row))
+		} else {
+			output.WriteString(normalStyle.Render("  " + row))
+		}
+		output.WriteString(" " + helpStyle.Render(fmt.Sprintf("%*d", benchmarkColumnWidth, bench.Iterations())))
+		for j, u := range units {
+			cell := "-"
+			if value, ok := bench.Value(u); ok {
+				cell = formatBenchmarkValue(value, u)
+			}
+			cell = fmt.Sprintf("%*s", benchmarkColumnWidth, cell)
+			if j == column {
+				output.WriteString(" " + metricStyle.Render(cell))
+			} else {
+				output.WriteString(" " + normalStyle.Render(cell))
+			}
+		}
+		if value, ok := bench.Value(unit); ok && barWidth > 0 && maxValue > 0 {
+			filled := int(value / maxValue * float64(barWidth))
+			if filled == 0 && value > 0 {
+				filled = 1
+			}
+			output.WriteString("  " + metricStyle.Render(strings.Repeat("█", filled)) +
+				separatorStyle.Render(strings.Repeat("░", barWidth-filled)))
+		}
+		output.WriteString("\n")
+	}
+
+	if len(result.Failed) > 0 {
+		output.WriteString("\n" + failStyle.Render("Failed: "+strings.Join(result.Failed, ", ")) + "\n")
+	}
+	if result.Count > 1 {
+		output.WriteString("\n" + helpStyle.Render(fmt.Sprintf("Values are the median of %d samples per benchmark", result.Count)) + "\n")
+	}
+	if state.notice != "" {
+		output.WriteString("\n" + metricStyle.Render(state.notice) + "\n")
+	}
+
+	return output.String(), cursorLine
+}
+
+// formatBenchmarkValue formats a benchmark value in the scale of its unit
+func formatBenchmarkValue(value float64, unit string) string {
+	switch unit {
+	case unitNsPerOp:
+		switch {
+		case value >= 1e9:
+			return fmt.Sprintf("%.4gs", value/1e9)
+		case value >= 1e6:
+			return fmt.Sprintf("%.4gms", value/1e6)
+		case value >= 1e3:
+			return fmt.Sprintf("%.4gµs", value/1e3)
+		}
+		return fmt.Sprintf("%.4gns", value)
+	case unitBytesPerOp:
+		switch {
+		case value >= 1<<30:
+			return fmt.Sprintf("%.4g GiB", value/(1<<30))
+		case value >= 1<<20:
+			return fmt.Sprintf("%.4g MiB", value/(1<<20))
+		case value >= 1<<10:
+			return fmt.Sprintf("%.4g KiB", value/(1<<10))
+		}
+		return fmt.Sprintf("%.0f B", value)
+	case unitAllocsPerOp:
+		return fmt.Sprintf("%.0f", value)
+	}
+	if value >= 1e4 {
+		// %g would switch to an exponent
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.4g", value)
+}
+
+// lastLines returns the last n lines of text
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// buildErrorContextLines is how many source lines are shown around each error
+const buildErrorContextLines = 2
+
+// BuildErrorViewState holds the state for the build error view
+type BuildErrorViewState struct {
+	packageName string
+	selected    int // Index of the diagnostic under the cursor
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+func newBuildErrorViewState(packageName string, returnTo appScreen) BuildErrorViewState {
+	return BuildErrorViewState{
+		packageName: packageName,
+		returnTo:    returnTo,
+	}
+}
+
+// FormatBuildErrors renders the compiler errors of a package, each with the
+// surrounding lines of its source file and a caret under the reported column
+// Returns the content and the line of the selected diagnostic's header
+func FormatBuildErrors(diagnostics []BuildDiagnostic, theme Theme, state BuildErrorViewState) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(failStyle.Render(fmt.Sprintf("%s - %d error(s)", buildFailStatus, len(diagnostics))) + "\n\n")
+
+	cursorLine := 0
+	for i, diagnostic := range diagnostics {
+		header := fmt.Sprintf("%s  %s", diagnostic.Location(), strings.SplitN(diagnostic.Message, "\n", 2)[0])
+		if i == state.selected {
+			cursorLine = strings.Count(output.String(), "\n")
+			output.WriteString(selectedStyle.Render(header) + "\n")
+		} else {
+			output.WriteString(failStyle.Render(header) + "\n")
+		}
+		// Continuation lines of the message, e.g. have/want
+		if parts := strings.SplitN(diagnostic.Message, "\n", 2); len(parts) > 1 {
+			for _, line := range strings.Split(parts[1], "\n") {
+				output.WriteString(normalStyle.Render("    "+line) + "\n")
+			}
+		}
+
+		source := readSourceContext(diagnostic.File, diagnostic.Line, buildErrorContextLines)
+		if source == nil {
+			output.WriteString(helpStyle.Render("    (source not available)") + "\n\n")
+			continue
+		}
+		for _, line := range source {
+			text := strings.ReplaceAll(line.Text, "\t", "    ")
+			gutter := fmt.Sprintf("%5d │ ", line.Number)
+			if line.Number != diagnostic.Line {
+				output.WriteString(helpStyle.Render(gutter) + normalStyle.Render(text) + "\n")
+				continue
+			}
+			output.WriteString(failStyle.Render(gutter) + normalStyle.Render(text) + "\n")
+			if diagnostic.Col > 0 {
+				output.WriteString(helpStyle.Render("      │ ") + failStyle.Render(strings.Repeat(" ", displayColumn(line.Text, diagnostic.Col))+"^") + "\n")
+			}
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String(), cursorLine
+}
+
+// displayColumn converts a 1-based byte column of a source line to the screen
+// column it is drawn at once tabs are expanded to four spaces
+func displayColumn(text string, col int) int {
+	column := 0
+	for i, r := range text {
+		if i >= col-1 {
+			break
+		}
+		if r == '\t' {
+			column += 4
+		} else {
+			column++
+		}
+	}
+	return column
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BuildDiagnostic is one compiler (or vet) error of a package that failed to build
+type BuildDiagnostic struct {
+	File    string // Absolute once resolved against the directory go test ran in
+	Line    int
+	Col     int // 0 if the tool didn't report a column
+	Message string
+}
+
+// appendBuildDiagnostics returns the diagnostics of both runs, each reported once
+// Diagnostics shared by both runs, e.g. when both builds fail the same way, aren't repeated
+func appendBuildDiagnostics(existing, more []BuildDiagnostic) []BuildDiagnostic {
+	var all []BuildDiagnostic
+	seen := make(map[BuildDiagnostic]bool)
+	for _, diag := range append(existing[:len(existing):len(existing)], more...) {
+		if !seen[diag] {
+			seen[diag] = true
+			all = append(all, diag)
+		}
+	}
+	return all
+}
+
+// Location returns the diagnostic's file:line:col
+func (d BuildDiagnostic) Location() string {
+	location := d.File + ":" + strconv.Itoa(d.Line)
+	if d.Col > 0 {
+		location += ":" + strconv.Itoa(d.Col)
+	}
+	return location
+}
+
+// buildFailStatus is the package status of a test binary that didn't compile
+const buildFailStatus = "BUILD FAIL"
+
+// diagnosticRegex matches "./b.go:4:13: undefined: c" and "b.go:4: message"
+var diagnosticRegex = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// parseBuildDiagnostics parses the build output of a package into diagnostics
+// Indented lines continue the message of the diagnostic before them
+func parseBuildDiagnostics(output string) []BuildDiagnostic {
+	var diagnostics []BuildDiagnostic
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if matches := diagnosticRegex.FindStringSubmatch(line); matches != nil {
+			lineNum, _ := strconv.Atoi(matches[2])
+			col, _ := strconv.Atoi(matches[3])
+			diagnostics = append(diagnostics, BuildDiagnostic{
+				File:    matches[1],
+				Line:    lineNum,
+				Col:     col,
+				Message: matches[4],
+			})
+			continue
+		}
+		if len(diagnostics) > 0 && strings.HasPrefix(line, "\t") {
+			// e.g. "\thave (int)\n\twant (string)"
+			last := &diagnostics[len(diagnostics)-1]
+			last.Message += "\n" + strings.TrimSpace(line)
+		}
+	}
+	return diagnostics
+}
+
+// resolveBuildDiagnostics makes the files of diagnostics absolute
+// go test reports them relative to the directory it ran in
+func resolveBuildDiagnostics(diagnostics []BuildDiagnostic, dir string) {
+	for i := range diagnostics {
+		if !filepath.IsAbs(diagnostics[i].File) {
+			diagnostics[i].File = filepath.Join(dir, diagnostics[i].File)
+		}
+	}
+}
+
+// sourceLine is a numbered line of a source file
+type sourceLine struct {
+	Number int
+	Text   string
+}
+
+// readSourceContext reads the lines of a file within radius of line
+// Returns nil if the file can't be read
+func readSourceContext(filePath string, line int, radius int) []sourceLine {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []sourceLine
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		if number < line-radius {
+			continue
+		}
+		if number > line+radius {
+			break
+		}
+		lines = append(lines, sourceLine{Number: number, Text: scanner.Text()})
+	}
+	return lines
+}
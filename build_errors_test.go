@@ -0,0 +1,118 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBuildDiagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []BuildDiagnostic
+	}{
+		{
+			name:   "no diagnostics",
+			output: "# example.com/app\n",
+		},
+		{
+			name:   "compiler errors with columns",
+			output: "# example.com/app [example.com/app.test]\n./b.go:4:13: undefined: c\n./b_test.go:9:2: declared and not used: x\n",
+			want: []BuildDiagnostic{
+				{File: "./b.go", Line: 4, Col: 13, Message: "undefined: c"},
+				{File: "./b_test.go", Line: 9, Col: 2, Message: "declared and not used: x"},
+			},
+		},
+		{
+			name:   "vet errors",
+			output: "# example.com/app\n# [example.com/app]\n./app_test.go:12:2: fmt.Errorf format %d has arg s of wrong type string\nb.go:7: possible misuse of unsafe.Pointer\n",
+			want: []BuildDiagnostic{
+				{File: "./app_test.go", Line: 12, Col: 2, Message: "fmt.Errorf format %d has arg s of wrong type string"},
+				{File: "b.go", Line: 7, Message: "possible misuse of unsafe.Pointer"},
+			},
+		},
+		{
+			name:   "indented lines continue the message",
+			output: "./a.go:10:9: cannot use x (variable of type int) as string value in return statement\n\thave (int)\n\twant (string)\n./a.go:12:1: missing return\n",
+			want: []BuildDiagnostic{
+				{File: "./a.go", Line: 10, Col: 9, Message: "cannot use x (variable of type int) as string value in return statement\nhave (int)\nwant (string)"},
+				{File: "./a.go", Line: 12, Col: 1, Message: "missing return"},
+			},
+		},
+		{
+			name:   "indented line before any diagnostic",
+			output: "\tnote: module requires Go 1.30\n./a.go:1:1: expected 'package', found 'EOF'\n",
+			want: []BuildDiagnostic{
+				{File: "./a.go", Line: 1, Col: 1, Message: "expected 'package', found 'EOF'"},
+			},
+		},
+		{
+			name:   "windows line endings",
+			output: "sub\\c.go:3:5: undefined: y\r\n",
+			want: []BuildDiagnostic{
+				{File: "sub\\c.go", Line: 3, Col: 5, Message: "undefined: y"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseBuildDiagnostics(tt.output)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d diagnostics, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("diagnostic %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAppendBuildDiagnostics(t *testing.T) {
+	a := BuildDiagnostic{File: "a.go", Line: 1, Col: 1, Message: "undefined: x"}
+	b := BuildDiagnostic{File: "b.go", Line: 2, Message: "missing return"}
+
+	tests := []struct {
+		name     string
+		existing []BuildDiagnostic
+		more     []BuildDiagnostic
+		want     []BuildDiagnostic
+	}{
+		{name: "both empty"},
+		{name: "distinct", existing: []BuildDiagnostic{a}, more: []BuildDiagnostic{b}, want: []BuildDiagnostic{a, b}},
+		{name: "shared", existing: []BuildDiagnostic{a, b}, more: []BuildDiagnostic{a}, want: []BuildDiagnostic{a, b}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appendBuildDiagnostics(tt.existing, tt.more)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("diagnostic %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+			if len(tt.existing) > 0 && tt.existing[0] != a {
+				t.Error("appendBuildDiagnostics modified existing")
+			}
+		})
+	}
+}
+
+func TestResolveBuildDiagnostics(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	absolute := filepath.Join(dir, "other", "c.go")
+	diagnostics := []BuildDiagnostic{{File: "b.go"}, {File: absolute}}
+
+	resolveBuildDiagnostics(diagnostics, dir)
+	if want := filepath.Join(dir, "b.go"); diagnostics[0].File != want {
+		t.Errorf("relative file resolved to %q, want %q", diagnostics[0].File, want)
+	}
+	if diagnostics[1].File != absolute {
+		t.Errorf("absolute file changed to %q", diagnostics[1].File)
+	}
+}
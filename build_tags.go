@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"go/build"
+	"go/build/constraint"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// knownOS and knownArch list the GOOS and GOARCH values go understands as build tags
+// They are evaluated against the current platform rather than treated as custom tags
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+	"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+	"windows": true, "zos": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+	"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+	"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+	"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+	"sparc": true, "sparc64": true, "wasm": true,
+}
+
+// unixOS lists the GOOS values that satisfy the "unix" build tag
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "linux": true, "netbsd": true,
+	"openbsd": true, "solaris": true,
+}
+
+// maxCustomTags bounds the tags of a single constraint searched for a minimal tag set
+const maxCustomTags = 10
+
+// isPlatformTag reports whether go sets or clears tag itself (OS, arch, toolchain,
+// release and cgo tags), as opposed to a custom tag passed with -tags
+func isPlatformTag(tag string) bool {
+	if knownOS[tag] || knownArch[tag] {
+		return true
+	}
+	switch tag {
+	case "unix", "cgo", "gc", "gccgo", "ignore":
+		return true
+	}
+	return strings.HasPrefix(tag, "go1.") || strings.HasPrefix(tag, "goexperiment.")
+}
+
+// platformTagSatisfied reports whether a platform tag holds for the current build
+func platformTagSatisfied(tag string) bool {
+	switch tag {
+	case runtime.GOOS, runtime.GOARCH, runtime.Compiler:
+		return true
+	case "unix":
+		return unixOS[runtime.GOOS]
+	case "cgo":
+		return build.Default.CgoEnabled
+	case "ignore":
+		return false
+	}
+	// android and illumos imply linux and solaris, ios implies darwin
+	if (tag == "linux" && runtime.GOOS == "android") ||
+		(tag == "solaris" && runtime.GOOS == "illumos") ||
+		(tag == "darwin" && runtime.GOOS == "ios") {
+		return true
+	}
+	for _, release := range build.Default.ReleaseTags {
+		if tag == release {
+			return true
+		}
+	}
+	for _, toolTag := range build.Default.ToolTags {
+		if tag == toolTag {
+			return true
+		}
+	}
+	return false
+}
+
+// readBuildConstraint reads the build constraint of a go file
+// A //go:build line takes precedence over legacy // +build lines, which are ANDed
+// Returns nil if the file has no constraint
+func readBuildConstraint(filePath string) constraint.Expr {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var goBuild constraint.Expr
+	var plusBuild []constraint.Expr
+
+	scanner := bufio.NewScanner(file)
+	inBlockComment := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Constraints may only be preceded by blank lines and comments
+		if inBlockComment {
+			if strings.Contains(line, "*/") {
+				inBlockComment = false
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "/*") {
+			inBlockComment = !strings.Contains(line, "*/")
+			continue
+		}
+		if !strings.HasPrefix(line, "//") {
+			// Stop at package declaration (or any other code)
+			break
+		}
+
+		if constraint.IsGoBuild(line) {
+			if expr, err := constraint.Parse(line); err == nil && goBuild == nil {
+				goBuild = expr
+			}
+		} else if constraint.IsPlusBuild(line) {
+			if expr, err := constraint.Parse(line); err == nil {
+				plusBuild = append(plusBuild, expr)
+			}
+		}
+	}
+
+	if goBuild != nil {
+		return goBuild
+	}
+	var combined constraint.Expr
+	for _, expr := range plusBuild {
+		if combined == nil {
+			combined = expr
+		} else {
+			combined = &constraint.AndExpr{X: combined, Y: expr}
+		}
+	}
+	return combined
+}
+
+// constraintTags returns the custom tags mentioned by a constraint, sorted
+func constraintTags(expr constraint.Expr) []string {
+	seen := make(map[string]bool)
+	var walk func(constraint.Expr)
+	walk = func(e constraint.Expr) {
+		switch x := e.(type) {
+		case *constraint.TagExpr:
+			if !isPlatformTag(x.Tag) {
+				seen[x.Tag] = true
+			}
+		case *constraint.NotExpr:
+			walk(x.X)
+		case *constraint.AndExpr:
+			walk(x.X)
+			walk(x.Y)
+		case *constraint.OrExpr:
+			walk(x.X)
+			walk(x.Y)
+		}
+	}
+	walk(expr)
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// minimalTagSet finds the smallest set of custom tags that satisfies a constraint
+// on the current platform
+// Returns an empty set for files built without tags (including negated ones like
+// !integration) and false if no combination of tags builds the file here
+func minimalTagSet(expr constraint.Expr) ([]string, bool) {
+	if expr == nil {
+		return nil, true
+	}
+
+	tags := constraintTags(expr)
+	if len(tags) > maxCustomTags {
+		tags = tags[:maxCustomTags]
+	}
+
+	// Try subsets in order of size so the first match is minimal
+	best := -1
+	for mask := 0; mask < 1<<len(tags); mask++ {
+		if best >= 0 && bitCount(mask) >= bitCount(best) {
+			continue
+		}
+		if buildsWithTags(expr, tagSubset(tags, mask)) {
+			best = mask
+		}
+	}
+	if best < 0 {
+		return nil, false
+	}
+
+	return tagSubset(tags, best), true
+}
+
+// tagSubset returns the tags selected by the bits of mask
+func tagSubset(tags []string, mask int) []string {
+	var subset []string
+	for i, tag := range tags {
+		if mask&(1<<i) != 0 {
+			subset = append(subset, tag)
+		}
+	}
+	return subset
+}
+
+// buildsWithTags reports whether a file with the given constraint is compiled
+// when go test runs with exactly the given custom tags on this platform
+func buildsWithTags(expr constraint.Expr, tags []string) bool {
+	if expr == nil {
+		return true
+	}
+	enabled := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		enabled[tag] = true
+	}
+	return expr.Eval(func(tag string) bool {
+		if isPlatformTag(tag) {
+			return platformTagSatisfied(tag)
+		}
+		return enabled[tag]
+	})
+}
+
+// bitCount returns the number of set bits in n
+func bitCount(n int) int {
+	count := 0
+	for ; n > 0; n &= n - 1 {
+		count++
+	}
+	return count
+}
+
+// addTagSet adds a tag set to sets unless an equal one is already present
+func addTagSet(sets [][]string, set []string) [][]string {
+	key := strings.Join(set, ",")
+	for _, existing := range sets {
+		if strings.Join(existing, ",") == key {
+			return sets
+		}
+	}
+	return append(sets, set)
+}
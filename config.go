@@ -6,18 +6,63 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	CurrentTheme         string
-	ThemesDirectory      string            // Custom path for themes (empty = use XDG default)
+	ThemesDirectory      string // Custom path for themes (empty = use XDG default)
 	LeftPanelWidth       int
 	MinPanelWidth        int
 	MaxPanelWidthPercent int
 	PanelResizeIncrement int
-	LogPath              string            // Path to log file (empty = no logging)
-	LogLevel             string            // Log level: debug, info, warn, error
-	TestModeByDir        map[string]string // Test mode per directory (absolute path -> mode)
+	LogPath              string                 // Path to log file (empty = no logging)
+	LogLevel             string                 // Log level: debug, info, warn, error
+	TestModeByDir        map[string]string      // Test mode per directory (absolute path -> mode)
+	MaxParallelPackages  int                    // Packages tested concurrently by Know It All (0 = GOMAXPROCS)
+	HangThresholdSeconds int                    // Running tests are flagged as possibly hung after this long (0 = disabled)
+	FlakyRunCount        int                    // Times Flaky Check runs each test (-count)
+	BenchmarkCount       int                    // Samples taken of each benchmark (-count)
+	FuzzTime             time.Duration          // Default -fuzztime offered in the fuzz view
+	StrategyByDir        map[string]string      // Know It All execution strategy per project (absolute scan path -> strategy)
+	CoverPkgByDir        map[string]string      // Cross-package coverage per project (absolute scan path -> "module" or -coverpkg patterns)
+	FlagProfileByDir     map[string]FlagProfile // Extra go test settings per directory (absolute path -> profile)
+}
+
+// FlagProfile holds the extra go test settings of a directory
+type FlagProfile struct {
+	Race      bool     // Run with -race
+	Shuffle   bool     // Run with -shuffle=on
+	Timeout   string   // -timeout value, e.g. "10m" (empty = go test default)
+	Tags      []string // Build tags added to those of the test mode
+	Env       []string // KEY=VALUE pairs added to the go test environment
+	ExtraArgs []string // Additional go test flags, passed as-is
+}
+
+// IsEmpty reports whether the profile changes nothing
+func (p FlagProfile) IsEmpty() bool {
+	return !p.Race && !p.Shuffle && p.Timeout == "" && len(p.Tags) == 0 && len(p.Env) == 0 && len(p.ExtraArgs) == 0
+}
+
+// String summarizes the profile as the flags it adds
+// Profiles with the same summary run identically
+func (p FlagProfile) String() string {
+	var parts []string
+	if p.Race {
+		parts = append(parts, "-race")
+	}
+	if p.Shuffle {
+		parts = append(parts, "-shuffle=on")
+	}
+	if p.Timeout != "" {
+		parts = append(parts, "-timeout="+p.Timeout)
+	}
+	if len(p.Tags) > 0 {
+		parts = append(parts, "tags:"+strings.Join(p.Tags, ","))
+	}
+	parts = append(parts, p.Env...)
+	parts = append(parts, p.ExtraArgs...)
+	return strings.Join(parts, " ")
 }
 
 func getConfigPath() string {
@@ -63,7 +108,14 @@ func LoadConfig(configPath string) Config {
 		PanelResizeIncrement: panelResizeIncrement,
 		LogPath:              "/tmp/gapistotle.log",
 		LogLevel:             "debug",
+		HangThresholdSeconds: defaultHangThresholdSeconds,
+		FlakyRunCount:        defaultFlakyRunCount,
+		BenchmarkCount:       defaultBenchmarkCount,
+		FuzzTime:             defaultFuzzTime,
 		TestModeByDir:        make(map[string]string),
+		StrategyByDir:        make(map[string]string),
+		CoverPkgByDir:        make(map[string]string),
+		FlagProfileByDir:     make(map[string]FlagProfile),
 	}
 
 	// Try to migrate from old location if new location doesn't exist
@@ -99,6 +151,25 @@ func LoadConfig(configPath string) Config {
 			continue
 		}
 
+		// Check for executionStrategy.* entries
+		if strings.HasPrefix(key, "executionStrategy.") {
+			dirPath := strings.TrimPrefix(key, "executionStrategy.")
+			config.StrategyByDir[dirPath] = value
+			continue
+		}
+
+		// Check for coverPkg.* entries
+		if strings.HasPrefix(key, "coverPkg.") {
+			dirPath := strings.TrimPrefix(key, "coverPkg.")
+			config.CoverPkgByDir[dirPath] = value
+			continue
+		}
+
+		// Check for flag profile entries (testRace.*, testShuffle.*, testTimeout.*, testTags.*, testEnv.*, testArgs.*)
+		if loadFlagProfileEntry(config.FlagProfileByDir, key, value) {
+			continue
+		}
+
 		switch key {
 		case "currentTheme":
 			config.CurrentTheme = value
@@ -124,6 +195,26 @@ func LoadConfig(configPath string) Config {
 			config.LogPath = value
 		case "logLevel":
 			config.LogLevel = value
+		case "maxParallelPackages":
+			if limit, err := strconv.Atoi(value); err == nil && limit >= 0 {
+				config.MaxParallelPackages = limit
+			}
+		case "hangThresholdSeconds":
+			if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+				config.HangThresholdSeconds = seconds
+			}
+		case "flakyRunCount":
+			if count, err := strconv.Atoi(value); err == nil && count >= 2 {
+				config.FlakyRunCount = count
+			}
+		case "benchmarkCount":
+			if count, err := strconv.Atoi(value); err == nil && count >= 1 {
+				config.BenchmarkCount = count
+			}
+		case "fuzzTime":
+			if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+				config.FuzzTime = duration
+			}
 		}
 	}
 
@@ -161,6 +252,16 @@ func SaveConfig(config Config, configPath string) error {
 	writer.WriteString("\n# Logging settings\n")
 	writer.WriteString("logPath=" + config.LogPath + "\n")
 	writer.WriteString("logLevel=" + config.LogLevel + "\n")
+	writer.WriteString("\n# Test execution settings (0 = GOMAXPROCS)\n")
+	writer.WriteString("maxParallelPackages=" + strconv.Itoa(config.MaxParallelPackages) + "\n")
+	writer.WriteString("# Flag running tests as possibly hung after this many seconds (0 = disabled)\n")
+	writer.WriteString("hangThresholdSeconds=" + strconv.Itoa(config.HangThresholdSeconds) + "\n")
+	writer.WriteString("# Times Flaky Check runs each test of a package\n")
+	writer.WriteString("flakyRunCount=" + strconv.Itoa(config.FlakyRunCount) + "\n")
+	writer.WriteString("# Samples taken of each benchmark (at least 6 for a 95% confidence interval)\n")
+	writer.WriteString("benchmarkCount=" + strconv.Itoa(config.BenchmarkCount) + "\n")
+	writer.WriteString("# Default time to fuzz a target for (-fuzztime)\n")
+	writer.WriteString("fuzzTime=" + config.FuzzTime.String() + "\n")
 
 	// Write test mode by directory
 	if len(config.TestModeByDir) > 0 {
@@ -170,9 +271,89 @@ func SaveConfig(config Config, configPath string) error {
 		}
 	}
 
+	// Write execution strategy by project
+	if len(config.StrategyByDir) > 0 {
+		writer.WriteString("\n# Know It All execution strategy per project (per-package or single)\n")
+		for dirPath, strategy := range config.StrategyByDir {
+			writer.WriteString("executionStrategy." + dirPath + "=" + strategy + "\n")
+		}
+	}
+
+	// Write cross-package coverage by project
+	if len(config.CoverPkgByDir) > 0 {
+		writer.WriteString("\n# Cross-package coverage per project (module or -coverpkg patterns)\n")
+		for dirPath, coverPkg := range config.CoverPkgByDir {
+			writer.WriteString("coverPkg." + dirPath + "=" + coverPkg + "\n")
+		}
+	}
+
+	// Write flag profiles by directory
+	if len(config.FlagProfileByDir) > 0 {
+		writer.WriteString("\n# go test flag profile per directory (testEnv may repeat)\n")
+		for dirPath, profile := range config.FlagProfileByDir {
+			if profile.Race {
+				writer.WriteString("testRace." + dirPath + "=true\n")
+			}
+			if profile.Shuffle {
+				writer.WriteString("testShuffle." + dirPath + "=true\n")
+			}
+			if profile.Timeout != "" {
+				writer.WriteString("testTimeout." + dirPath + "=" + profile.Timeout + "\n")
+			}
+			if len(profile.Tags) > 0 {
+				writer.WriteString("testTags." + dirPath + "=" + strings.Join(profile.Tags, ",") + "\n")
+			}
+			for _, env := range profile.Env {
+				writer.WriteString("testEnv." + dirPath + "=" + env + "\n")
+			}
+			if len(profile.ExtraArgs) > 0 {
+				writer.WriteString("testArgs." + dirPath + "=" + strings.Join(profile.ExtraArgs, " ") + "\n")
+			}
+		}
+	}
+
 	return writer.Flush()
 }
 
+// loadFlagProfileEntry applies a flag profile config entry to profiles
+// Returns false if key is not a flag profile entry
+func loadFlagProfileEntry(profiles map[string]FlagProfile, key, value string) bool {
+	prefixes := []string{"testRace.", "testShuffle.", "testTimeout.", "testTags.", "testEnv.", "testArgs."}
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		dirPath := strings.TrimPrefix(key, prefix)
+		profile := profiles[dirPath]
+		switch prefix {
+		case "testRace.":
+			profile.Race = value == "true"
+		case "testShuffle.":
+			profile.Shuffle = value == "true"
+		case "testTimeout.":
+			profile.Timeout = value
+		case "testTags.":
+			profile.Tags = splitTagList(value)
+		case "testEnv.":
+			if strings.Contains(value, "=") {
+				profile.Env = append(profile.Env, value)
+			}
+		case "testArgs.":
+			profile.ExtraArgs = strings.Fields(value)
+		}
+		profiles[dirPath] = profile
+		return true
+	}
+	return false
+}
+
+// splitTagList splits a comma or space separated list of build tags
+func splitTagList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
 // migrateOldConfig attempts to migrate from old config location (~/.gapistotle.conf)
 func migrateOldConfig(newPath string) {
 	homeDir, err := os.UserHomeDir()
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// coverPkgModule is the cross-package coverage setting that measures every
+// package of the scanned module
+const coverPkgModule = "module"
+
+// coverageProfile holds the blocks of a coverage profile by file
+// Files are keyed by their import path, e.g. "example.com/app/store/store.go"
+type coverageProfile map[string][]coverageBlock
+
+// blockPosition identifies a coverage block within its file
+type blockPosition struct {
+	startLine, startCol, endLine, endCol int
+}
+
+// applyCrossPackageCoverage parses the profile of a -coverpkg run, which covers
+// every package matching the patterns and not just the one under test
+// The package's own files are parsed like a regular profile; their blocks and
+// those of the other files the tests ran are kept so attributeCoverage can
+// credit the tests of other packages
+func applyCrossPackageCoverage(ctx context.Context, result *PackageTestResult, coverageFile string, packageDir string) {
+	if _, statErr := os.Stat(coverageFile); statErr != nil {
+		LogWarn("Coverage file not found",
+			"coverage_file", coverageFile,
+			"error", statErr,
+		)
+		return
+	}
+
+	// Files of other packages share base names with the package's own, so its
+	// share of the profile is picked out by import path
+	importPath, err := resolveImportPath(ctx, packageDir)
+	if err != nil {
+		LogWarn("Failed to resolve import path for cross-package coverage", "package", result.PackagePath, "error", err)
+		return
+	}
+	result.ImportPath = importPath
+	result.CoverageBlocks = contributedBlocks(readCoverageBlocks(coverageFile), importPath)
+
+	if profile, exists := splitCoverageProfile(coverageFile)[importPath]; exists {
+		applyBatchCoverage(result, profile, packageDir)
+	}
+	// go test reports the coverage of every measured package; keep the package's own
+	result.Coverage = statementCoverage(result.FileCoverages)
+
+	LogDebug("Parsed cross-package coverage",
+		"package", result.PackagePath,
+		"import_path", importPath,
+		"coverpkg", result.CoverPkg,
+		"file_count", len(result.CoverageBlocks),
+	)
+}
+
+// resolveImportPath returns the import path of the package in packageDir
+func resolveImportPath(ctx context.Context, packageDir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-e", "-f", "{{.ImportPath}}", ".")
+	cmd.Dir = packageDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go list failed: %w", err)
+	}
+	importPath := strings.TrimSpace(string(output))
+	if importPath == "" || strings.HasPrefix(importPath, "_") {
+		return "", fmt.Errorf("%s is not in a module", packageDir)
+	}
+	return importPath, nil
+}
+
+// readCoverageBlocks reads every block of a coverage profile by file
+func readCoverageBlocks(profilePath string) coverageProfile {
+	file, err := os.Open(profilePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	// Format: importpath/file.go:startline.startcol,endline.endcol numstatements count
+	lineRegex := regexp.MustCompile(`^(.+\.go):(\d+\.\d+),(\d+\.\d+)\s+(\d+)\s+(\d+)`)
+
+	profile := make(coverageProfile)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		matches := lineRegex.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+		block := coverageBlock{}
+		block.startLine, block.startCol = parseLineColumn(matches[2])
+		block.endLine, block.endCol = parseLineColumn(matches[3])
+		block.numStmt, _ = strconv.Atoi(matches[4])
+		block.count, _ = strconv.Atoi(matches[5])
+		profile[matches[1]] = append(profile[matches[1]], block)
+	}
+	return profile
+}
+
+// contributedBlocks keeps the blocks of the package's own files, which list every
+// block of the package, and of the other files the run executed any of
+// A -coverpkg profile lists every file of the module; files the tests never
+// reached add nothing to any package's coverage
+func contributedBlocks(profile coverageProfile, importPath string) coverageProfile {
+	kept := make(coverageProfile)
+	for file, blocks := range profile {
+		if path.Dir(file) == importPath || blocksRan(blocks) {
+			kept[file] = blocks
+		}
+	}
+	return kept
+}
+
+// addBlockCounts adds the counts of blocks to merged, matching blocks by position
+// Blocks merged doesn't have yet are appended
+func addBlockCounts(merged []coverageBlock, blocks []coverageBlock) []coverageBlock {
+	index := make(map[blockPosition]int, len(merged))
+	for i, block := range merged {
+		index[block.position()] = i
+	}
+	for _, block := range blocks {
+		if i, exists := index[block.position()]; exists {
+			merged[i].count += block.count
+			continue
+		}
+		index[block.position()] = len(merged)
+		merged = append(merged, block)
+	}
+	return merged
+}
+
+// position returns where the block is in its file
+func (b coverageBlock) position() blockPosition {
+	return blockPosition{b.startLine, b.startCol, b.endLine, b.endCol}
+}
+
+// blocksRan reports whether any of the blocks ran
+func blocksRan(blocks []coverageBlock) bool {
+	for _, block := range blocks {
+		if block.count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// statementCoverage returns the percentage of statements covered across files
+func statementCoverage(files []FileCoverage) float64 {
+	var covered, total int
+	for _, fc := range files {
+		covered += fc.CoveredLines
+		total += fc.TotalLines
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100.0
+}
+
+// coverageContribution is the blocks of a file recorded by one package's run
+type coverageContribution struct {
+	testPackage string
+	file        string
+	blocks      []coverageBlock
+}
+
+// attributeCoverage merges cross-package coverage after the result of package
+// changed is stored
+// Only the packages whose coverage the change can affect are recomputed: those
+// the new run measured and those the package contributed to before
+// The blocks of every run are indexed by package in one pass, and each affected
+// package is rebuilt from its own run's blocks plus those of every other run,
+// so results can be stored again in any order
+func attributeCoverage(results map[string]*PackageTestResult, changed string) {
+	touched := make(map[string]bool)
+	if result, exists := results[changed]; exists {
+		if result.ImportPath != "" {
+			touched[result.ImportPath] = true
+		}
+		for file := range result.CoverageBlocks {
+			touched[path.Dir(file)] = true
+		}
+	}
+	for _, result := range results {
+		if result.ImportPath != "" && containsString(result.CoverageFrom, changed) {
+			touched[result.ImportPath] = true
+		}
+	}
+	if len(touched) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	owners := make(map[string]*PackageTestResult)            // Import path -> result measured with -coverpkg
+	contributions := make(map[string][]coverageContribution) // Import path -> blocks of its files, by run
+	for _, name := range names {
+		result := results[name]
+		if result.ImportPath != "" && result.CoverageBlocks != nil {
+			owners[result.ImportPath] = result
+		}
+		for file, blocks := range result.CoverageBlocks {
+			if importPath := path.Dir(file); touched[importPath] {
+				contributions[importPath] = append(contributions[importPath], coverageContribution{name, file, blocks})
+			}
+		}
+	}
+
+	for importPath := range touched {
+		if result, exists := owners[importPath]; exists {
+			mergeCoverage(result, contributions[importPath])
+		}
+	}
+}
+
+// mergeCoverage recomputes the per-file, per-function and total coverage of a
+// package from the blocks every run recorded for its files, summing their counts,
+// and records the test packages that ran any of them
+func mergeCoverage(result *PackageTestResult, contributions []coverageContribution) {
+	files := make(coverageProfile)
+	fileContributors := make(map[string][]string)
+	var contributors []string
+	for _, contribution := range contributions {
+		files[contribution.file] = addBlockCounts(files[contribution.file], contribution.blocks)
+		if blocksRan(contribution.blocks) {
+			fileContributors[contribution.file] = append(fileContributors[contribution.file], contribution.testPackage)
+			if !containsString(contributors, contribution.testPackage) {
+				contributors = append(contributors, contribution.testPackage)
+			}
+		}
+	}
+
+	fileCoverages := make([]FileCoverage, 0, len(files))
+	for file, blocks := range files {
+		fc := FileCoverage{FileName: path.Base(file), Blocks: blocks, CoveredBy: fileContributors[file]}
+		for _, block := range blocks {
+			fc.TotalLines += block.numStmt
+			if block.count > 0 {
+				fc.CoveredLines += block.numStmt
+			}
+		}
+		if fc.TotalLines > 0 {
+			fc.CoveragePercent = float64(fc.CoveredLines) / float64(fc.TotalLines) * 100.0
+		}
+		fileCoverages = append(fileCoverages, fc)
+	}
+	// Worst first, like parseCoverageProfile
+	sort.Slice(fileCoverages, func(i, j int) bool {
+		if fileCoverages[i].CoveragePercent != fileCoverages[j].CoveragePercent {
+			return fileCoverages[i].CoveragePercent < fileCoverages[j].CoveragePercent
+		}
+		return fileCoverages[i].FileName < fileCoverages[j].FileName
+	})
+
+	result.FileCoverages = fileCoverages
+	result.Coverage = statementCoverage(fileCoverages)
+	result.FunctionCoverages = attributeFunctionCoverage(result.FunctionCoverages, fileCoverages)
+	result.CoverageFrom = contributors
+}
+
+// attributeFunctionCoverage recomputes function coverage from merged file blocks
+// Functions span from their line to the next function of the file, as in
+// parseFunctionCoverage
+func attributeFunctionCoverage(functions []FunctionCoverage, files []FileCoverage) []FunctionCoverage {
+	blocksByFile := make(map[string][]coverageBlock, len(files))
+	var totalPackageStmts int
+	for _, fc := range files {
+		blocksByFile[fc.FileName] = fc.Blocks
+		totalPackageStmts += fc.TotalLines
+	}
+
+	attributed := make([]FunctionCoverage, len(functions))
+	for i, fn := range functions {
+		endLine := math.MaxInt
+		for _, other := range functions {
+			if other.FileName == fn.FileName && other.Line > fn.Line && other.Line < endLine {
+				endLine = other.Line
+			}
+		}
+
+		fn.TotalStmts, fn.UncoveredStmts = 0, 0
+		for _, block := range blocksByFile[fn.FileName] {
+			if block.startLine >= fn.Line && block.startLine < endLine {
+				fn.TotalStmts += block.numStmt
+				if block.count == 0 {
+					fn.UncoveredStmts += block.numStmt
+				}
+			}
+		}
+		if fn.TotalStmts > 0 {
+			fn.CoveragePercent = float64(fn.TotalStmts-fn.UncoveredStmts) / float64(fn.TotalStmts) * 100.0
+		}
+		fn.ImpactPercent = 0
+		if totalPackageStmts > 0 {
+			fn.ImpactPercent = float64(fn.UncoveredStmts) / float64(totalPackageStmts) * 100.0
+		}
+		attributed[i] = fn
+	}
+
+	// Most uncovered statements first, like parseFunctionCoverage
+	sort.Slice(attributed, func(i, j int) bool {
+		return attributed[i].UncoveredStmts > attributed[j].UncoveredStmts
+	})
+	return attributed
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Coverage of a source line, from the profile blocks that touch it
+type lineCoverageState int
+
+const (
+	lineUncounted lineCoverageState = iota // No block: comments, declarations, blank lines
+	lineCovered                            // Every block on the line ran
+	lineUncovered                          // No block on the line ran
+	linePartial                            // Some blocks on the line ran and some didn't
+)
+
+// lineCoverage is the coverage of one source line
+type lineCoverage struct {
+	state lineCoverageState
+	hits  int // Most times a block on the line ran
+}
+
+// partialStyle colors lines that are only partly covered
+var partialStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffff00"))
+
+// coverageSource is a source file opened in the coverage view
+type coverageSource struct {
+	file   FileCoverage
+	path   string
+	lines  []string
+	err    error
+	blocks []lineRange // Uncovered blocks, in line order
+}
+
+// lineRange is a range of source lines, both ends included and counted from 1
+type lineRange struct {
+	start, end int
+}
+
+// CoverageViewState holds the state for the coverage view: the per-file coverage
+// list of a package and the source of the file opened from it
+type CoverageViewState struct {
+	packageName string
+	packageDir  string
+	selected    int             // File under the cursor in the list
+	listScroll  int             // Scroll of the file list, kept while a file is open
+	source      *coverageSource // File being viewed, nil while the list is shown
+	scroll      int             // Scroll of the source
+	block       int             // Uncovered block last jumped to, -1 before the first jump
+	returnTo    appScreen       // Screen to go back to on ESC
+}
+
+// openCoverageSource reads a file of a package for the source view
+func openCoverageSource(packageDir string, file FileCoverage) *coverageSource {
+	source := &coverageSource{file: file, path: filepath.Join(packageDir, file.FileName)}
+	data, err := os.ReadFile(source.path)
+	if err != nil {
+		LogWarn("Failed to read source for coverage view", "path", source.path, "error", err)
+		source.err = err
+		return source
+	}
+	source.lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	source.blocks = uncoveredBlocks(file.Blocks)
+	return source
+}
+
+// coverageByLine returns the coverage of every line of a file, indexed from 0
+// A block covers the lines from its start to its end position
+func coverageByLine(blocks []coverageBlock, lineCount int) []lineCoverage {
+	lines := make([]lineCoverage, lineCount)
+	for _, block := range blocks {
+		for line := block.startLine; line <= block.endLine && line <= lineCount; line++ {
+			if line < 1 {
+				continue
+			}
+			coverage := &lines[line-1]
+			ran := block.count > 0
+			switch {
+			case coverage.state == lineUncounted && ran:
+				coverage.state = lineCovered
+			case coverage.state == lineUncounted:
+				coverage.state = lineUncovered
+			case (coverage.state == lineCovered && !ran) || (coverage.state == lineUncovered && ran):
+				coverage.state = linePartial
+			}
+			coverage.hits = Max(coverage.hits, block.count)
+		}
+	}
+	return lines
+}
+
+// uncoveredBlocks returns the line ranges of blocks that never ran, merging
+// blocks on adjacent lines so a jump lands on each gap once
+func uncoveredBlocks(blocks []coverageBlock) []lineRange {
+	var ranges []lineRange
+	for _, block := range blocks {
+		if block.count == 0 {
+			ranges = append(ranges, lineRange{block.startLine, block.endLine})
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+
+	var merged []lineRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.start <= merged[n-1].end+1 {
+			merged[n-1].end = Max(merged[n-1].end, r.end)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// coverageIndicator returns the marker and color of a coverage percentage
+// [!!] = poor (<50%), [**] = medium (50-80%), [++] = good (>=80%)
+func coverageIndicator(percent float64, passStyle, failStyle lipgloss.Style) (string, lipgloss.Style) {
+	if percent >= 80.0 {
+		return "[++]", passStyle
+	} else if percent >= 50.0 {
+		return "[**]", partialStyle
+	}
+	return "[!!]", failStyle
+}
+
+// FormatCoverageFiles renders the per-file coverage list of a package with a cursor
+// Returns the content and the line of the selected file
+func FormatCoverageFiles(result *PackageTestResult, theme Theme, state CoverageViewState) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
+	passStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00"))
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(normalStyle.Render("Coverage: "+result.PackagePath) +
+		metricStyle.Render(fmt.Sprintf("  %.1f%%", result.Coverage)) + "\n")
+	if result.CoverPkg != "" {
+		output.WriteString(helpStyle.Render("Measured with -coverpkg="+result.CoverPkg+"; counts include the tests of every package listed per file") + "\n")
+	}
+	output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
+	if len(result.FileCoverages) == 0 {
+		output.WriteString(normalStyle.Render("The run recorded no coverage.") + "\n")
+		return output.String(), 0
+	}
+
+	cursorLine := 0
+	for i, fc := range result.FileCoverages {
+		indicator, style := coverageIndicator(fc.CoveragePercent, passStyle, failStyle)
+		row := fmt.Sprintf("%-40s %6.1f%% (%d/%d stmts)", fc.FileName, fc.CoveragePercent, fc.CoveredLines, fc.TotalLines)
+		if len(fc.CoveredBy) > 0 {
+			row += " by " + strings.Join(fc.CoveredBy, ", ")
+		}
+		if i == state.selected {
+			cursorLine = strings.Count(output.String(), "\n")
+			output.WriteString(style.Render(testCursorMarker+indicator) + " " + selectedStyle.Render(row) + "\n")
+		} else {
+			output.WriteString(style.Render("  "+indicator) + " " + normalStyle.Render(row) + "\n")
+		}
+	}
+	output.WriteString("\n" + helpStyle.Render("Enter opens the file with its covered and uncovered lines") + "\n")
+	return output.String(), cursorLine
+}
+
+// FormatCoverageSource renders a source file colored by coverage, with line
+// numbers and hit counts in the gutter
+// The lines of the uncovered block last jumped to are marked in the gutter
+// Returns the content and the line the current uncovered block starts on
+func FormatCoverageSource(source *coverageSource, theme Theme, state CoverageViewState, width int) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
+	passStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00"))
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+
+	file := source.file
+	_, style := coverageIndicator(file.CoveragePercent, passStyle, failStyle)
+	output.WriteString(normalStyle.Render(source.path) +
+		style.Render(fmt.Sprintf("  %.1f%%", file.CoveragePercent)) +
+		metricStyle.Render(fmt.Sprintf(" (%d/%d stmts)", file.CoveredLines, file.TotalLines)) + "\n")
+	if len(file.CoveredBy) > 0 {
+		output.WriteString(helpStyle.Render("Hit counts from the tests of "+strings.Join(file.CoveredBy, ", ")) + "\n")
+	}
+	if source.err != nil {
+		output.WriteString("\n" + failStyle.Render("Failed to read the file: "+source.err.Error()) + "\n")
+		return output.String(), 0
+	}
+
+	blockInfo := fmt.Sprintf("%d uncovered block(s)", len(source.blocks))
+	if state.block >= 0 && state.block < len(source.blocks) {
+		blockInfo = fmt.Sprintf("uncovered block %d of %d", state.block+1, len(source.blocks))
+	}
+	output.WriteString(passStyle.Render("covered") + "  " + failStyle.Render("uncovered") + "  " +
+		partialStyle.Render("partly covered") + "  " + normalStyle.Render("not counted") +
+		helpStyle.Render("  | "+blockInfo) + "\n")
+	output.WriteString(separatorStyle.Render(strings.Repeat("─", Max(width, 20))) + "\n")
+
+	current := lineRange{-1, -1}
+	if state.block >= 0 && state.block < len(source.blocks) {
+		current = source.blocks[state.block]
+	}
+	numberWidth := len(strconv.Itoa(len(source.lines)))
+	textWidth := Max(width-numberWidth-11, 10)
+
+	cursorLine := 0
+	coverage := coverageByLine(file.Blocks, len(source.lines))
+	for i, text := range source.lines {
+		lineNumber := i + 1
+		marker := "  "
+		if lineNumber >= current.start && lineNumber <= current.end {
+			marker = failStyle.Render("▌ ")
+			if lineNumber == current.start {
+				cursorLine = strings.Count(output.String(), "\n")
+			}
+		}
+
+		hits := ""
+		textStyle := normalStyle
+		switch coverage[i].state {
+		case lineCovered:
+			hits = strconv.Itoa(coverage[i].hits) + "x"
+			textStyle = passStyle
+		case lineUncovered:
+			hits = "0"
+			textStyle = failStyle
+		case linePartial:
+			hits = strconv.Itoa(coverage[i].hits) + "x"
+			textStyle = partialStyle
+		}
+
+		text = strings.ReplaceAll(strings.TrimRight(text, " \t\r"), "\t", "    ")
+		if runes := []rune(text); len(runes) > textWidth {
+			text = string(runes[:textWidth-1]) + "…"
+		}
+		output.WriteString(marker + helpStyle.Render(fmt.Sprintf("%*d", numberWidth, lineNumber)) +
+			metricStyle.Render(fmt.Sprintf(" %6s ", hits)) + separatorStyle.Render("│ ") + textStyle.Render(text) + "\n")
+	}
+	return output.String(), cursorLine
+}
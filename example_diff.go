@@ -0,0 +1,164 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// exampleTestGroup is the details section examples are listed in
+// "#" can't appear in a build tag, so it never collides with a tagged test type
+const exampleTestGroup = "#example"
+
+// isExampleTest reports whether a test is an Example function (or part of one)
+func isExampleTest(name string) bool {
+	return strings.HasPrefix(strings.SplitN(name, "/", 2)[0], "Example")
+}
+
+// ExampleMismatch is the output of a failed example against its // Output: comment
+type ExampleMismatch struct {
+	Got       []string
+	Want      []string
+	Unordered bool // // Unordered output: the lines may come in any order
+}
+
+// parseExampleMismatch extracts the got and want blocks the testing package
+// prints for a failed example:
+//
+//	--- FAIL: ExampleFoo (0.00s)
+//	got:
+//	...
+//	want:
+//	...
+//
+// Returns nil if the output has no such blocks, e.g. when the example panicked
+// The last "want:" line ends the got block, so output containing "want:" still parses
+func parseExampleMismatch(output string) *ExampleMismatch {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	gotLine := -1
+	for i, line := range lines {
+		if line == "got:" {
+			gotLine = i
+			break
+		}
+	}
+	if gotLine < 0 {
+		return nil
+	}
+
+	wantLine := -1
+	unordered := false
+	for i := len(lines) - 1; i > gotLine; i-- {
+		if lines[i] == "want:" || lines[i] == "want (unordered):" {
+			wantLine = i
+			unordered = lines[i] == "want (unordered):"
+			break
+		}
+	}
+	if wantLine < 0 {
+		return nil
+	}
+
+	return &ExampleMismatch{
+		Got:       trimTrailingBlankLines(lines[gotLine+1 : wantLine]),
+		Want:      trimTrailingBlankLines(lines[wantLine+1:]),
+		Unordered: unordered,
+	}
+}
+
+// trimTrailingBlankLines drops empty lines from the end of lines
+func trimTrailingBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLine is one line of a unified diff
+type diffLine struct {
+	Op   byte // ' ' in both, '-' only in want, '+' only in got
+	Text string
+}
+
+// Diff returns a line diff from want to got
+// Unordered output is compared as sorted lines, so only missing and unexpected
+// lines show up
+func (e *ExampleMismatch) Diff() []diffLine {
+	want, got := e.Want, e.Got
+	if e.Unordered {
+		want = sortedLines(want)
+		got = sortedLines(got)
+	}
+	return diffLines(want, got)
+}
+
+// sortedLines returns a sorted copy of lines
+func sortedLines(lines []string) []string {
+	sorted := make([]string, len(lines))
+	copy(sorted, lines)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// diffLines returns a minimal line diff from a to b, from their longest common subsequence
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = Max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, diffLine{'-', a[i]})
+			i++
+		default:
+			diff = append(diff, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, diffLine{'+', b[j]})
+	}
+	return diff
+}
+
+// renderExampleDiff renders the diff of a failed example's expected and actual output
+func renderExampleDiff(mismatch *ExampleMismatch, output *strings.Builder, failStyle, passStyle, normalStyle, metricStyle lipgloss.Style) {
+	heading := "  Output differs (-want +got):"
+	if mismatch.Unordered {
+		heading = "  Unordered output differs (-want +got, lines sorted):"
+	}
+	output.WriteString(normalStyle.Render(heading) + "\n")
+	for _, line := range mismatch.Diff() {
+		text := "      " + string(line.Op) + " " + line.Text
+		switch line.Op {
+		case '-':
+			output.WriteString(failStyle.Render(text) + "\n")
+		case '+':
+			output.WriteString(passStyle.Render(text) + "\n")
+		default:
+			output.WriteString(metricStyle.Render(text) + "\n")
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// diffText renders a diff as one "<op><text>" string per line
+func diffText(diff []diffLine) []string {
+	lines := make([]string, len(diff))
+	for i, line := range diff {
+		lines[i] = string(line.Op) + line.Text
+	}
+	return lines
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []string
+	}{
+		{name: "both empty"},
+		{name: "equal", a: []string{"x", "y"}, b: []string{"x", "y"}, want: []string{" x", " y"}},
+		{name: "only in want", a: []string{"x", "y"}, want: []string{"-x", "-y"}},
+		{name: "only in got", b: []string{"x", "y"}, want: []string{"+x", "+y"}},
+		{name: "changed line", a: []string{"x", "y", "z"}, b: []string{"x", "Y", "z"}, want: []string{" x", "-y", "+Y", " z"}},
+		{name: "inserted line", a: []string{"x", "z"}, b: []string{"x", "y", "z"}, want: []string{" x", "+y", " z"}},
+		{name: "removed line", a: []string{"x", "y", "z"}, b: []string{"x", "z"}, want: []string{" x", "-y", " z"}},
+		{
+			name: "longest common subsequence is kept",
+			a:    []string{"a", "b", "c", "d"},
+			b:    []string{"b", "c", "e", "a"},
+			want: []string{"-a", " b", " c", "-d", "+e", "+a"},
+		},
+		{name: "repeated lines", a: []string{"x", "x"}, b: []string{"x", "x", "x"}, want: []string{" x", " x", "+x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffText(diffLines(tt.a, tt.b))
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("diffLines(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExampleMismatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		wantNil       bool
+		wantGot       []string
+		wantWant      []string
+		wantUnordered bool
+	}{
+		{
+			name:     "ordered output",
+			output:   "--- FAIL: ExampleHello (0.00s)\ngot:\nhello\nworld\nwant:\nhello\nthere\n",
+			wantGot:  []string{"hello", "world"},
+			wantWant: []string{"hello", "there"},
+		},
+		{
+			name:          "unordered output",
+			output:        "--- FAIL: ExampleSet (0.00s)\ngot:\nb\na\nwant (unordered):\na\nc\n",
+			wantGot:       []string{"b", "a"},
+			wantWant:      []string{"a", "c"},
+			wantUnordered: true,
+		},
+		{
+			name:     "output containing want:",
+			output:   "--- FAIL: ExampleWant (0.00s)\ngot:\nwant:\nx\nwant:\ny\n",
+			wantGot:  []string{"want:", "x"},
+			wantWant: []string{"y"},
+		},
+		{
+			name:     "trailing blank lines",
+			output:   "got:\nx\n\nwant:\ny\n\n",
+			wantGot:  []string{"x"},
+			wantWant: []string{"y"},
+		},
+		{
+			name:    "panicked example",
+			output:  "--- FAIL: ExamplePanic (0.00s)\npanic: boom\n",
+			wantNil: true,
+		},
+		{
+			name:    "got without want",
+			output:  "got:\nx\n",
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatch := parseExampleMismatch(tt.output)
+			if tt.wantNil {
+				if mismatch != nil {
+					t.Fatalf("parseExampleMismatch() = %+v, want nil", mismatch)
+				}
+				return
+			}
+			if mismatch == nil {
+				t.Fatal("parseExampleMismatch() = nil, want a mismatch")
+			}
+			if strings.Join(mismatch.Got, "\n") != strings.Join(tt.wantGot, "\n") {
+				t.Errorf("Got = %q, want %q", mismatch.Got, tt.wantGot)
+			}
+			if strings.Join(mismatch.Want, "\n") != strings.Join(tt.wantWant, "\n") {
+				t.Errorf("Want = %q, want %q", mismatch.Want, tt.wantWant)
+			}
+			if mismatch.Unordered != tt.wantUnordered {
+				t.Errorf("Unordered = %v, want %v", mismatch.Unordered, tt.wantUnordered)
+			}
+		})
+	}
+}
+
+func TestExampleMismatchDiffUnordered(t *testing.T) {
+	mismatch := &ExampleMismatch{Got: []string{"c", "a"}, Want: []string{"a", "b"}, Unordered: true}
+	got := diffText(mismatch.Diff())
+	want := []string{" a", "-b", "+c"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// FailureKind says why a package's run failed, so triage can start from the
+// right place instead of a bare FAIL
+type FailureKind string
+
+const (
+	FailureNone    FailureKind = ""
+	FailureTests   FailureKind = "tests"   // One or more tests failed
+	FailureBuild   FailureKind = "build"   // The test binary didn't compile
+	FailureVet     FailureKind = "vet"     // The vet checks go test runs before testing failed
+	FailurePanic   FailureKind = "panic"   // A test panicked and took the binary down
+	FailureTimeout FailureKind = "timeout" // -timeout expired
+	FailureExit    FailureKind = "exit"    // The binary exited non-zero without a failing test (e.g. os.Exit in TestMain)
+	FailureSignal  FailureKind = "signal"  // The test binary or go test was killed by a signal
+)
+
+// signalRegex matches the line go test prints when the test binary is killed,
+// e.g. "signal: killed" or "signal: segmentation fault"
+var signalRegex = regexp.MustCompile(`^signal: (.+)$`)
+
+// isVetOutput reports whether build output comes from vet rather than the compiler
+// go test heads vet's findings with "# [import/path]", the compiler's with "# import/path"
+func isVetOutput(output string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "# [") {
+			return true
+		}
+	}
+	return false
+}
+
+// exitSignal returns how go test ended when a signal killed it, e.g. "signal: killed"
+// Returns "" if it exited on its own
+func exitSignal(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == -1 {
+		return exitErr.ProcessState.String()
+	}
+	return ""
+}
+
+// classifyFailure sets the failure kind and detail of a finished result from
+// what the parser saw and the error go test exited with
+// The most specific cause wins: a panic also fails its test, a timeout panics
+func (p *testEventParser) classifyFailure(err error) {
+	result := p.result
+	result.FailureKind = FailureNone
+	result.FailureDetail = ""
+
+	switch result.Status {
+	case "FAIL", buildFailStatus, "FLAKY":
+	default:
+		return
+	}
+
+	switch {
+	case p.failedBuild != "" && p.vetFailed:
+		result.FailureKind = FailureVet
+		result.FailureDetail = fmt.Sprintf("%d vet finding(s)", len(result.BuildErrors))
+	case p.failedBuild != "":
+		result.FailureKind = FailureBuild
+		result.FailureDetail = fmt.Sprintf("%d compile error(s)", len(result.BuildErrors))
+		if len(result.BuildErrors) == 0 {
+			// Not a compiler error, e.g. "build constraints exclude all Go files"
+			result.FailureDetail = p.buildMessage()
+		}
+	case result.Timeout != nil:
+		result.FailureKind = FailureTimeout
+		result.FailureDetail = fmt.Sprintf("timed out after %s with %d test(s) running", result.Timeout.After, len(result.Timeout.RunningTests))
+	case result.Panic != nil:
+		result.FailureKind = FailurePanic
+		result.FailureDetail = "panic: " + result.Panic.Message
+		if result.Panic.Test != "" {
+			result.FailureDetail += " in " + result.Panic.Test
+		}
+	case p.signal != "":
+		result.FailureKind = FailureSignal
+		result.FailureDetail = "killed by signal: " + p.signal
+		if p.signalTest != "" {
+			result.FailureDetail += " in " + p.signalTest
+		}
+	case result.FailedTests > 0 || result.FlakyTests > 0:
+		result.FailureKind = FailureTests
+		result.FailureDetail = testFailureDetail(result)
+	case exitSignal(err) != "":
+		result.FailureKind = FailureSignal
+		result.FailureDetail = "go test " + exitSignal(err)
+	default:
+		result.FailureKind = FailureExit
+		result.FailureDetail = "test binary exited non-zero with no failing test (os.Exit or log.Fatal in TestMain?)"
+	}
+}
+
+// buildMessage returns the first line of the failed build's output that isn't
+// a "# import/path" header
+func (p *testEventParser) buildMessage() string {
+	if builder, ok := p.buildOutput[p.failedBuild]; ok {
+		for _, line := range strings.Split(builder.String(), "\n") {
+			if line != "" && !strings.HasPrefix(line, "# ") {
+				return line
+			}
+		}
+	}
+	return "build failed"
+}
+
+// testFailureDetail describes a failure caused by the tests themselves
+func testFailureDetail(result *PackageTestResult) string {
+	detail := fmt.Sprintf("%d test(s) failed", result.FailedTests)
+	if result.FlakyTests > 0 {
+		detail += fmt.Sprintf(", %d flaky", result.FlakyTests)
+	}
+	return detail
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FlagProfileField is one editable setting of a flag profile
+type FlagProfileField struct {
+	Name        string
+	Description string
+	Toggle      bool // Toggled with Enter instead of typed
+	GetValue    func(*FlagProfile) string
+	SetValue    func(*FlagProfile, string) error
+}
+
+// FlagProfileEditorState holds the state for the flag profile editor
+type FlagProfileEditorState struct {
+	dirPath       string // Absolute directory whose profile is edited
+	packageName   string // Package the editor was opened for
+	profile       FlagProfile
+	fields        []FlagProfileField
+	selectedField int
+	editing       bool   // Whether the selected field is being typed into
+	input         string // Text input for the selected field
+	inputError    string // Error message if the input is invalid
+}
+
+func newFlagProfileEditorState(dirPath string, packageName string, profile FlagProfile) FlagProfileEditorState {
+	fields := []FlagProfileField{
+		{
+			Name:        "Race Detector",
+			Description: "Run with -race",
+			Toggle:      true,
+			GetValue: func(p *FlagProfile) string {
+				if p.Race {
+					return "on"
+				}
+				return "off"
+			},
+			SetValue: func(p *FlagProfile, _ string) error {
+				p.Race = !p.Race
+				return nil
+			},
+		},
+		{
+			Name:        "Shuffle",
+			Description: "Run tests in random order with -shuffle=on; the seed is shown with the results",
+			Toggle:      true,
+			GetValue: func(p *FlagProfile) string {
+				if p.Shuffle {
+					return "on"
+				}
+				return "off"
+			},
+			SetValue: func(p *FlagProfile, _ string) error {
+				p.Shuffle = !p.Shuffle
+				return nil
+			},
+		},
+		{
+			Name:        "Timeout",
+			Description: "-timeout duration, e.g. 10m (empty = go test default)",
+			GetValue:    func(p *FlagProfile) string { return p.Timeout },
+			SetValue: func(p *FlagProfile, value string) error {
+				if strings.ContainsAny(value, " \t") {
+					return fmt.Errorf("timeout must be a single duration like 10m")
+				}
+				p.Timeout = value
+				return nil
+			},
+		},
+		{
+			Name:        "Build Tags",
+			Description: "Comma separated tags added to the test mode's tags, e.g. e2e,slow",
+			GetValue:    func(p *FlagProfile) string { return strings.Join(p.Tags, ",") },
+			SetValue: func(p *FlagProfile, value string) error {
+				p.Tags = splitTagList(value)
+				return nil
+			},
+		},
+		{
+			Name:        "Environment",
+			Description: "Space separated KEY=VALUE pairs, e.g. DATABASE_URL=postgres://localhost/test",
+			GetValue:    func(p *FlagProfile) string { return strings.Join(p.Env, " ") },
+			SetValue: func(p *FlagProfile, value string) error {
+				env := strings.Fields(value)
+				for _, pair := range env {
+					if !strings.Contains(pair, "=") || strings.HasPrefix(pair, "=") {
+						return fmt.Errorf("%q is not a KEY=VALUE pair", pair)
+					}
+				}
+				p.Env = env
+				return nil
+			},
+		},
+		{
+			Name:        "Extra Args",
+			Description: "Space separated go test flags passed as-is, e.g. -failfast -p=1",
+			GetValue:    func(p *FlagProfile) string { return strings.Join(p.ExtraArgs, " ") },
+			SetValue: func(p *FlagProfile, value string) error {
+				args := strings.Fields(value)
+				for _, arg := range args {
+					if !strings.HasPrefix(arg, "-") {
+						return fmt.Errorf("%q is not a flag", arg)
+					}
+				}
+				p.ExtraArgs = args
+				return nil
+			},
+		},
+	}
+
+	return FlagProfileEditorState{
+		dirPath:     dirPath,
+		packageName: packageName,
+		profile:     profile,
+		fields:      fields,
+	}
+}
+
+// StartEditing begins editing the selected field, or toggles it for toggle fields
+// Returns true if the profile changed
+func (fe *FlagProfileEditorState) StartEditing() bool {
+	field := fe.fields[fe.selectedField]
+	if field.Toggle {
+		field.SetValue(&fe.profile, "")
+		return true
+	}
+	fe.editing = true
+	fe.input = field.GetValue(&fe.profile)
+	fe.inputError = ""
+	return false
+}
+
+// ApplyInput stores the typed input in the selected field
+// Returns false and sets inputError if the input is invalid
+func (fe *FlagProfileEditorState) ApplyInput() bool {
+	field := fe.fields[fe.selectedField]
+	if err := field.SetValue(&fe.profile, strings.TrimSpace(fe.input)); err != nil {
+		fe.inputError = err.Error()
+		return false
+	}
+	fe.editing = false
+	fe.input = ""
+	fe.inputError = ""
+	return true
+}
+
+// CancelEditing discards the typed input
+func (fe *FlagProfileEditorState) CancelEditing() {
+	fe.editing = false
+	fe.input = ""
+	fe.inputError = ""
+}
+
+// ClearSelectedField resets the selected field to its default
+func (fe *FlagProfileEditorState) ClearSelectedField() {
+	field := fe.fields[fe.selectedField]
+	if field.Toggle {
+		if field.GetValue(&fe.profile) == "on" {
+			field.SetValue(&fe.profile, "")
+		}
+		return
+	}
+	field.SetValue(&fe.profile, "")
+}
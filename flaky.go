@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// aggregateRepeatedRuns collapses the entries of tests run several times with
+// -count into one entry per test, keeping the order tests first ran in
+// Tests that both passed and failed are marked FLAKY; the output of a test that
+// failed at least once is that of its failing runs
+func aggregateRepeatedRuns(result *PackageTestResult, count int) {
+	var order []string
+	runsByName := make(map[string][]TestResult)
+	for _, test := range result.Tests {
+		if _, seen := runsByName[test.Name]; !seen {
+			order = append(order, test.Name)
+		}
+		runsByName[test.Name] = append(runsByName[test.Name], test)
+	}
+
+	tests := make([]TestResult, 0, len(order))
+	for _, name := range order {
+		tests = append(tests, aggregateTestRuns(runsByName[name]))
+	}
+	result.Tests = tests
+	result.RunCount = count
+	recountTests(result)
+}
+
+// aggregateTestRuns combines the runs of a single test
+func aggregateTestRuns(runs []TestResult) TestResult {
+	aggregate := runs[0]
+	aggregate.Runs = 0
+	aggregate.Failures = 0
+
+	var total time.Duration
+	var passes int
+	var failedOutput strings.Builder
+	lastStatus := ""
+	for i, run := range runs {
+		if run.Hung {
+			aggregate.Hung = true
+		}
+		if i > 0 {
+			aggregate.Races = append(aggregate.Races, run.Races...)
+		}
+		if run.Panic != nil {
+			aggregate.Panic = run.Panic
+		}
+		switch run.Status {
+		case "PASS":
+			passes++
+		case "FAIL":
+			aggregate.Failures++
+			fmt.Fprintf(&failedOutput, "--- run %d of %d failed ---\n", i+1, len(runs))
+			failedOutput.WriteString(run.Output)
+		case "SKIP":
+		default:
+			// Never finished (cancelled or killed)
+			continue
+		}
+		aggregate.Runs++
+		total += run.Duration
+		lastStatus = run.Status
+		aggregate.Output = run.Output
+	}
+
+	if aggregate.Runs == 0 {
+		// No run finished - keep the running entry as it was
+		return runs[len(runs)-1]
+	}
+	aggregate.Duration = total / time.Duration(aggregate.Runs)
+	aggregate.Races = dedupRaces(aggregate.Races)
+
+	switch {
+	case aggregate.Failures > 0 && passes > 0:
+		aggregate.Status = "FLAKY"
+		aggregate.Output = failedOutput.String()
+	case aggregate.Failures > 0:
+		aggregate.Status = "FAIL"
+		aggregate.Output = failedOutput.String()
+	default:
+		aggregate.Status = lastStatus
+	}
+	return aggregate
+}
+
+// FailureRate returns the fraction of a repeated test's runs that failed
+func (t TestResult) FailureRate() float64 {
+	if t.Runs == 0 {
+		return 0
+	}
+	return float64(t.Failures) / float64(t.Runs)
+}
+
+// applyFlakyStatus marks a failed package FLAKY when every failure came from
+// tests that also passed in other runs
+func applyFlakyStatus(result *PackageTestResult) {
+	if result.Status == "FAIL" && result.FailedTests == 0 && result.FlakyTests > 0 {
+		result.Status = "FLAKY"
+	}
+}
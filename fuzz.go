@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress lines the fuzzing engine prints every few seconds
+var (
+	// "fuzz: elapsed: 6s, execs: 197875 (35881/sec), new interesting: 0 (total: 1)"
+	fuzzProgressRegex = regexp.MustCompile(`^fuzz: elapsed: (\S+), execs: (\d+) \((\d+)/sec\), new interesting: (\d+) \(total: (\d+)\)`)
+	// "fuzz: elapsed: 0s, gathering baseline coverage: 1/1 completed, now fuzzing with 8 workers"
+	fuzzBaselineRegex = regexp.MustCompile(`^fuzz: elapsed: (\S+), gathering baseline coverage: (\d+)/(\d+) completed(?:, now fuzzing with (\d+) workers)?`)
+	// "fuzz: minimizing 35-byte failing input file" and "fuzz: elapsed: 0s, minimizing"
+	fuzzMinimizingRegex = regexp.MustCompile(`^fuzz: (?:elapsed: \S+, )?minimizing`)
+)
+
+// Lines the testing package prints when fuzzing finds a failing input
+var (
+	fuzzInputWrittenRegex = regexp.MustCompile(`^\s*Failing input written to (\S+)`)
+	fuzzRerunRegex        = regexp.MustCompile(`^\s*go test -run=(\S+)`)
+	fuzzSeedFailureRegex  = regexp.MustCompile(`^failure while testing seed corpus entry: (\S+)`)
+)
+
+// Fuzzing phases, in the order the engine goes through them
+const (
+	fuzzPhaseBaseline   = "gathering baseline coverage"
+	fuzzPhaseFuzzing    = "fuzzing"
+	fuzzPhaseMinimizing = "minimizing failing input"
+)
+
+// fuzzHistoryLength is how many execs/sec samples are kept for the rate graph
+const fuzzHistoryLength = 60
+
+// FuzzTargets returns the names of the package's fuzz targets (FuzzXxx functions)
+func (p TestPackage) FuzzTargets() []string {
+	var targets []string
+	for _, fn := range p.TestFuncs {
+		if strings.HasPrefix(fn.Name, "Fuzz") {
+			targets = append(targets, fn.Name)
+		}
+	}
+	return targets
+}
+
+// FuzzCrasher is an input that made a fuzz target fail
+type FuzzCrasher struct {
+	InputPath string   // Corpus file relative to the package directory, e.g. "testdata/fuzz/FuzzX/8f9a..."; empty for a seed entry
+	RunName   string   // Test name that reruns the input, e.g. "FuzzX/8f9a..." or "FuzzX/seed#0"
+	Failure   []string // What the failing test reported
+	Input     string   // Contents of the corpus file
+}
+
+// FuzzResult is the outcome of fuzzing one target
+type FuzzResult struct {
+	PackagePath    string
+	Target         string
+	Status         string // "PASS", "FAIL", "BUILD FAIL", "RUNNING", "CANCELLED"
+	Phase          string // Current fuzzing phase while running
+	FuzzTime       time.Duration
+	Elapsed        time.Duration // As last reported by the engine
+	Execs          int64
+	ExecsPerSec    int64
+	NewInteresting int // Inputs that expanded coverage during this run
+	Corpus         int // Interesting inputs in total, including the seed and cached corpus
+	BaselineDone   int
+	BaselineTotal  int
+	Workers        int
+	RateHistory    []int64 // Recent execs/sec samples, oldest first
+	Crasher        *FuzzCrasher
+	Started        time.Time
+	Duration       time.Duration
+	FullOutput     string
+	BuildErrors    []BuildDiagnostic
+}
+
+// fuzzParser incrementally builds a FuzzResult from go test -json events
+type fuzzParser struct {
+	result      *FuzzResult
+	output      strings.Builder             // Plain text of every output event
+	pending     string                      // Output after the last newline
+	failing     bool                        // A --- FAIL line was seen; later lines describe the failure
+	buildOutput map[string]*strings.Builder // Build output per import path being built
+	failedBuild string                      // Import path whose build failure failed the package, if any
+}
+
+// newFuzzParser creates a parser that accumulates events into result
+func newFuzzParser(result *FuzzResult) *fuzzParser {
+	return &fuzzParser{
+		result:      result,
+		buildOutput: make(map[string]*strings.Builder),
+	}
+}
+
+// Consume applies a single test event to the result being built
+func (p *fuzzParser) Consume(event TestEvent) {
+	result := p.result
+
+	switch event.Action {
+	case "build-output":
+		builder, ok := p.buildOutput[event.ImportPath]
+		if !ok {
+			builder = &strings.Builder{}
+			p.buildOutput[event.ImportPath] = builder
+		}
+		builder.WriteString(event.Output)
+		p.output.WriteString(event.Output)
+
+	case "output":
+		p.output.WriteString(event.Output)
+		p.pending += event.Output
+		for {
+			newline := strings.IndexByte(p.pending, '\n')
+			if newline < 0 {
+				break
+			}
+			p.parseLine(p.pending[:newline])
+			p.pending = p.pending[newline+1:]
+		}
+
+	case "pass", "fail", "skip":
+		if event.Test == "" {
+			result.Duration = time.Duration(event.Elapsed * float64(time.Second))
+			if event.FailedBuild != "" {
+				p.failedBuild = event.FailedBuild
+				if builder, ok := p.buildOutput[event.FailedBuild]; ok {
+					result.BuildErrors = parseBuildDiagnostics(builder.String())
+				}
+			}
+		}
+	}
+}
+
+// parseLine records a progress line or a detail of a failing input
+func (p *fuzzParser) parseLine(line string) {
+	result := p.result
+	line = strings.TrimRight(line, "\r")
+
+	if matches := fuzzProgressRegex.FindStringSubmatch(line); matches != nil {
+		result.Phase = fuzzPhaseFuzzing
+		result.Elapsed, _ = time.ParseDuration(matches[1])
+		result.Execs, _ = strconv.ParseInt(matches[2], 10, 64)
+		result.ExecsPerSec, _ = strconv.ParseInt(matches[3], 10, 64)
+		result.NewInteresting, _ = strconv.Atoi(matches[4])
+		result.Corpus, _ = strconv.Atoi(matches[5])
+		result.RateHistory = append(result.RateHistory, result.ExecsPerSec)
+		if len(result.RateHistory) > fuzzHistoryLength {
+			result.RateHistory = result.RateHistory[len(result.RateHistory)-fuzzHistoryLength:]
+		}
+		return
+	}
+	if matches := fuzzBaselineRegex.FindStringSubmatch(line); matches != nil {
+		result.Phase = fuzzPhaseBaseline
+		result.Elapsed, _ = time.ParseDuration(matches[1])
+		result.BaselineDone, _ = strconv.Atoi(matches[2])
+		result.BaselineTotal, _ = strconv.Atoi(matches[3])
+		result.Corpus = result.BaselineTotal
+		if matches[4] != "" {
+			result.Phase = fuzzPhaseFuzzing
+			result.Workers, _ = strconv.Atoi(matches[4])
+		}
+		return
+	}
+	if fuzzMinimizingRegex.MatchString(line) {
+		result.Phase = fuzzPhaseMinimizing
+		return
+	}
+
+	if matches := fuzzSeedFailureRegex.FindStringSubmatch(line); matches != nil {
+		p.crasher().RunName = matches[1]
+		return
+	}
+	if matches := fuzzInputWrittenRegex.FindStringSubmatch(line); matches != nil {
+		p.crasher().InputPath = matches[1]
+		p.failing = false
+		return
+	}
+	if matches := fuzzRerunRegex.FindStringSubmatch(line); matches != nil {
+		p.crasher().RunName = matches[1]
+		return
+	}
+
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(trimmed, "--- FAIL: "):
+		p.failing = true
+	case p.failing && trimmed != "" && trimmed != "To re-run:" && trimmed != "FAIL" &&
+		!strings.HasPrefix(trimmed, "exit status ") && !strings.HasPrefix(trimmed, "FAIL\t"):
+		crasher := p.crasher()
+		crasher.Failure = append(crasher.Failure, trimmed)
+	}
+}
+
+// crasher returns the failing input being described, creating it on first use
+func (p *fuzzParser) crasher() *FuzzCrasher {
+	if p.result.Crasher == nil {
+		p.result.Crasher = &FuzzCrasher{}
+	}
+	return p.result.Crasher
+}
+
+// RunFuzz fuzzes one target of a package for fuzzTime; tests are skipped
+// mode selects the build tags, as for tests
+// onEvent, if non-nil, is called for every event as it is read from go test
+// Cancelling ctx kills the go test process group; the partial result is returned
+// with status "CANCELLED"
+func RunFuzz(ctx context.Context, packageDir string, packageName string, target string, fuzzTime time.Duration, mode testMode, opts testRunOptions, onEvent func(TestEvent)) (*FuzzResult, error) {
+	LogInfo("Fuzzing",
+		"package", packageName,
+		"directory", packageDir,
+		"target", target,
+		"fuzztime", fuzzTime.String(),
+		"mode", mode,
+		"profile", opts.profile.String(),
+	)
+
+	result := &FuzzResult{
+		PackagePath: packageName,
+		Target:      target,
+		Status:      "RUNNING",
+		FuzzTime:    fuzzTime,
+		Started:     time.Now(),
+	}
+
+	if mode == testModeAll {
+		mode = tagsMode(opts.allTags)
+	}
+	// -run=^$ keeps the other tests out; the target's seed corpus still runs first
+	opts.runPattern = "^$"
+	args := []string{"test", "-json", "-fuzz=^" + regexp.QuoteMeta(target) + "$", "-fuzztime=" + fuzzTime.String()}
+	modeArgs, _ := testModeArgs(mode, opts.profile.Tags)
+	args = append(args, modeArgs...)
+	args = append(args, opts.args()...)
+	args = append(args, ".")
+
+	cmd, stdout, stderr, err := startGoTest(ctx, packageDir, args, opts.profile.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := newFuzzParser(result)
+	readTestOutput(stdout, func(line string) {
+		if event, ok := decodeTestEvent(line); ok {
+			parser.Consume(event)
+			if onEvent != nil {
+				onEvent(event)
+			}
+		}
+	})
+
+	err = waitGoTest(cmd)
+	result.FullOutput = parser.output.String() + stderr.String()
+	result.Phase = ""
+	resolveBuildDiagnostics(result.BuildErrors, packageDir)
+
+	// A failing entry of the seed corpus may be a crasher an earlier run wrote to testdata
+	if crasher := result.Crasher; crasher != nil && crasher.InputPath == "" && crasher.RunName != "" {
+		inputPath := "testdata/fuzz/" + crasher.RunName
+		if _, statErr := os.Stat(filepath.Join(packageDir, filepath.FromSlash(inputPath))); statErr == nil {
+			crasher.InputPath = inputPath
+		}
+	}
+	if crasher := result.Crasher; crasher != nil && crasher.InputPath != "" {
+		data, readErr := os.ReadFile(filepath.Join(packageDir, filepath.FromSlash(crasher.InputPath)))
+		if readErr != nil {
+			LogWarn("Failed to read failing fuzz input", "path", crasher.InputPath, "error", readErr)
+		} else {
+			crasher.Input = string(data)
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		result.Status = "CANCELLED"
+	case parser.failedBuild != "":
+		result.Status = buildFailStatus
+	case err != nil, result.Crasher != nil:
+		result.Status = "FAIL"
+	default:
+		result.Status = "PASS"
+	}
+
+	LogInfo("Fuzzing complete",
+		"package", packageName,
+		"target", target,
+		"status", result.Status,
+		"execs", result.Execs,
+		"new_interesting", result.NewInteresting,
+		"crasher", result.Crasher != nil,
+		"duration", result.Duration.String(),
+	)
+	return result, nil
+}
+
+// fuzzKey identifies the fuzz results of one target of a package
+func fuzzKey(packageName string, target string) string {
+	return packageName + "\x00" + target
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fuzzDurations are the -fuzztime choices offered in the fuzz view
+var fuzzDurations = []time.Duration{
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+}
+
+// sparkBlocks draw the execs/sec graph, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// FuzzViewState holds the state for the fuzz view
+type FuzzViewState struct {
+	packageName string
+	selected    int           // Fuzz target under the cursor
+	duration    time.Duration // -fuzztime of the next run
+	notice      string        // Outcome of the last action
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+func newFuzzViewState(packageName string, duration time.Duration, returnTo appScreen) FuzzViewState {
+	return FuzzViewState{
+		packageName: packageName,
+		duration:    duration,
+		returnTo:    returnTo,
+	}
+}
+
+// nextFuzzDuration returns the duration choice after (step 1) or before (step -1) current
+func nextFuzzDuration(current time.Duration, step int) time.Duration {
+	if step > 0 {
+		for _, d := range fuzzDurations {
+			if d > current {
+				return d
+			}
+		}
+		return current
+	}
+	for i := len(fuzzDurations) - 1; i >= 0; i-- {
+		if fuzzDurations[i] < current {
+			return fuzzDurations[i]
+		}
+	}
+	return current
+}
+
+// FormatFuzz renders the fuzz targets of a package and the progress or outcome
+// of fuzzing the selected one
+// results maps target names to their live or last result
+// Returns the content and the line of the selected target
+func FormatFuzz(packageName string, targets []string, results map[string]*FuzzResult, theme Theme, state FuzzViewState, width int) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	headerStyle := lipgloss.NewStyle().Foreground(theme.NormalFg).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(normalStyle.Render("Fuzz targets: "+packageName) + "\n\n")
+	if len(targets) == 0 {
+		output.WriteString(normalStyle.Render("No fuzz targets (FuzzXxx functions) in this package.") + "\n")
+		return output.String(), 0
+	}
+
+	cursorLine := 0
+	for i, target := range targets {
+		if i == state.selected {
+			cursorLine = strings.Count(output.String(), "\n")
+			output.WriteString(selectedStyle.Render(testCursorMarker + target))
+		} else {
+			output.WriteString(normalStyle.Render("  " + target))
+		}
+		if result, exists := results[target]; exists {
+			output.WriteString("  " + styledStatus(result.Status, theme))
+			if result.Crasher != nil {
+				output.WriteString(" " + failStyle.Render("crasher"))
+			}
+		}
+		output.WriteString("\n")
+	}
+	output.WriteString("\n" + normalStyle.Render("Fuzz time: ") + metricStyle.Render(state.duration.String()) +
+		helpStyle.Render("  (←→ to change, Enter to fuzz the selected target)") + "\n")
+	output.WriteString(separatorStyle.Render(strings.Repeat("─", Max(width, 20))) + "\n")
+
+	target := targets[Clamp(state.selected, 0, len(targets)-1)]
+	result, exists := results[target]
+	if !exists {
+		output.WriteString(helpStyle.Render(target+" hasn't been fuzzed yet.") + "\n")
+		return output.String(), cursorLine
+	}
+
+	output.WriteString(headerStyle.Render(target) + "  " + styledStatus(result.Status, theme) + "\n")
+	switch {
+	case result.Status == "RUNNING":
+		phase := "starting"
+		if result.Phase != "" {
+			phase = result.Phase
+		}
+		if result.Phase == fuzzPhaseBaseline && result.BaselineTotal > 0 {
+			phase += fmt.Sprintf(" (%d/%d)", result.BaselineDone, result.BaselineTotal)
+		}
+		output.WriteString(normalStyle.Render("Phase: ") + metricStyle.Render(phase) +
+			normalStyle.Render(fmt.Sprintf(" (%s of %s)", time.Since(result.Started).Round(time.Second), result.FuzzTime)) + "\n")
+	case result.Duration > 0:
+		output.WriteString(normalStyle.Render("Elapsed Time: ") + metricStyle.Render(formatDuration(result.Duration)) + "\n")
+	}
+	if result.Workers > 0 {
+		output.WriteString(normalStyle.Render("Workers: ") + metricStyle.Render(fmt.Sprintf("%d", result.Workers)) + "\n")
+	}
+	if result.Execs > 0 {
+		output.WriteString(normalStyle.Render("Executions: ") + metricStyle.Render(fmt.Sprintf("%d", result.Execs)) +
+			normalStyle.Render(" at ") + metricStyle.Render(fmt.Sprintf("%d/sec", result.ExecsPerSec)) + "\n")
+	}
+	if result.Execs > 0 || result.Corpus > 0 {
+		output.WriteString(normalStyle.Render("New interesting: ") + metricStyle.Render(fmt.Sprintf("%d", result.NewInteresting)) +
+			normalStyle.Render("  Corpus: ") + metricStyle.Render(fmt.Sprintf("%d", result.Corpus)) + "\n")
+	}
+	if len(result.RateHistory) > 1 {
+		output.WriteString(normalStyle.Render("Execs/sec: ") + metricStyle.Render(sparkline(result.RateHistory, Max(width-12, 10))) + "\n")
+	}
+
+	if len(result.BuildErrors) > 0 {
+		output.WriteString("\n" + failStyle.Render(fmt.Sprintf("The test binary failed to build (%d error(s)):", len(result.BuildErrors))) + "\n")
+		for _, diagnostic := range result.BuildErrors {
+			output.WriteString(metricStyle.Render("  "+shortLocation(diagnostic.Location())) + normalStyle.Render("  "+diagnostic.Message) + "\n")
+		}
+		return output.String(), cursorLine
+	}
+
+	if crasher := result.Crasher; crasher != nil {
+		output.WriteString("\n" + failStyle.Render("Fuzzing found a failing input") + "\n")
+		for _, line := range crasher.Failure {
+			output.WriteString(normalStyle.Render("  "+line) + "\n")
+		}
+		if crasher.InputPath != "" {
+			output.WriteString("\n" + normalStyle.Render("Input file: ") + metricStyle.Render(crasher.InputPath) + "\n")
+			if crasher.Input != "" {
+				for _, line := range strings.Split(strings.TrimRight(crasher.Input, "\n"), "\n") {
+					output.WriteString(separatorStyle.Render("  │ ") + normalStyle.Render(line) + "\n")
+				}
+			}
+		}
+		if crasher.RunName != "" {
+			output.WriteString("\n" + helpStyle.Render("Press t to rerun it as a regular test (go test -run="+crasher.RunName+")") + "\n")
+		}
+	} else if result.Status == "FAIL" {
+		output.WriteString("\n" + failStyle.Render("Fuzzing failed. Output:") + "\n")
+		output.WriteString(normalStyle.Render(lastLines(result.FullOutput, 30)) + "\n")
+	}
+
+	if state.notice != "" {
+		output.WriteString("\n" + metricStyle.Render(state.notice) + "\n")
+	}
+	return output.String(), cursorLine
+}
+
+// sparkline draws values as a row of block characters scaled to the largest,
+// keeping the most recent values that fit in width
+func sparkline(values []int64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	var maxValue int64
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		level := 0
+		if maxValue > 0 {
+			level = int(v * int64(len(sparkBlocks)-1) / maxValue)
+		}
+		sb.WriteRune(sparkBlocks[level])
+	}
+	return sb.String()
+}
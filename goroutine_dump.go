@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// GoroutineDumpState holds the state for the goroutine dump browser
+type GoroutineDumpState struct {
+	packageName string
+	selected    int          // Index of the goroutine under the cursor
+	expanded    map[int]bool // Goroutine index -> whether its frames are shown
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+func newGoroutineDumpState(packageName string, returnTo appScreen) GoroutineDumpState {
+	return GoroutineDumpState{
+		packageName: packageName,
+		expanded:    make(map[int]bool),
+		returnTo:    returnTo,
+	}
+}
+
+// FormatGoroutineDump renders a timeout panic's goroutines, collapsed to one line
+// each unless expanded
+// Goroutines running one of the timed out tests are marked and listed first
+// Returns the content and the line of the selected goroutine's header
+func FormatGoroutineDump(timeout *TimeoutPanic, theme Theme, state GoroutineDumpState) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(failStyle.Render("panic: test timed out after "+timeout.After) + "\n")
+	for _, running := range timeout.RunningTests {
+		output.WriteString(normalStyle.Render(fmt.Sprintf("  running: %s (%s)", running.Name, running.Duration)) + "\n")
+	}
+	output.WriteString("\n")
+
+	cursorLine := 0
+	lineCount := strings.Count(output.String(), "\n")
+	for i, goroutine := range orderedGoroutines(timeout) {
+		marker := "▸ "
+		if state.expanded[i] {
+			marker = "▾ "
+		}
+
+		header := fmt.Sprintf("%sgoroutine %d [%s]", marker, goroutine.ID, goroutine.State)
+		if len(goroutine.Frames) > 0 {
+			header += "  " + goroutine.Frames[0].Function
+		}
+		if test := timedOutTest(goroutine, timeout); test != "" {
+			header += "  ← " + test
+		}
+
+		if i == state.selected {
+			cursorLine = lineCount
+			output.WriteString(selectedStyle.Render(header) + "\n")
+		} else if timedOutTest(goroutine, timeout) != "" {
+			output.WriteString(failStyle.Render(header) + "\n")
+		} else {
+			output.WriteString(normalStyle.Render(header) + "\n")
+		}
+		lineCount++
+
+		if !state.expanded[i] {
+			continue
+		}
+		for _, frame := range goroutine.Frames {
+			output.WriteString(metricStyle.Render("    "+frame.Function+"()") + "\n")
+			output.WriteString(helpStyle.Render("        "+frame.Location()) + "\n")
+			lineCount += 2
+		}
+		if goroutine.CreatedBy != nil {
+			output.WriteString(normalStyle.Render("    created by "+goroutine.CreatedBy.Function) + "\n")
+			output.WriteString(helpStyle.Render("        "+goroutine.CreatedBy.Location()) + "\n")
+			lineCount += 2
+		}
+	}
+
+	return output.String(), cursorLine
+}
+
+// orderedGoroutines returns the goroutines of a timeout with those running a
+// timed out test first, keeping dump order otherwise
+func orderedGoroutines(timeout *TimeoutPanic) []Goroutine {
+	var testGoroutines, others []Goroutine
+	for _, goroutine := range timeout.Goroutines {
+		if timedOutTest(goroutine, timeout) != "" {
+			testGoroutines = append(testGoroutines, goroutine)
+		} else {
+			others = append(others, goroutine)
+		}
+	}
+	return append(testGoroutines, others...)
+}
+
+// timedOutTest returns the timed out test a goroutine is running, or ""
+func timedOutTest(goroutine Goroutine, timeout *TimeoutPanic) string {
+	for _, running := range timeout.RunningTests {
+		if goroutine.runsTest(running.Name) {
+			return running.Name
+		}
+	}
+	return ""
+}
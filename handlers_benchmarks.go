@@ -0,0 +1,222 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// benchmarkViewResult returns the benchmark result the view shows: the live
+// result while the package is benchmarking, its last finished run otherwise
+func (m *model) benchmarkViewResult(packageName string) (*PackageBenchmarkResult, bool) {
+	if parser, running := m.liveBenchmarks[packageName]; running {
+		return parser.result, true
+	}
+	result, exists := m.benchmarkResults[packageName]
+	return result, exists
+}
+
+// openBenchmarks opens the benchmark view for a package, starting a run when the
+// package hasn't been benchmarked yet
+func openBenchmarks(m *model, pkg TestPackage, run bool) tea.Cmd {
+	if m.benchmarkView.packageName != pkg.Name || m.currentScreen != screenBenchmarks {
+		returnTo := m.currentScreen
+		if returnTo == screenTestsMenu {
+			returnTo = screenMain
+		}
+		m.benchmarkView = newBenchmarkViewState(pkg.Name, returnTo)
+	}
+	m.currentScreen = screenBenchmarks
+
+	if _, exists := m.benchmarkViewResult(pkg.Name); exists && !run {
+		return nil
+	}
+	if m.packageBusy(pkg.Name) {
+		return nil
+	}
+	LogInfo("Benchmarking package", "package", pkg.Name)
+	return m.startPackageBenchmarks(pkg)
+}
+
+// findTestPackage returns the scanned package with the given name
+func findTestPackage(packages []TestPackage, name string) (TestPackage, bool) {
+	for _, pkg := range packages {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return TestPackage{}, false
+}
+
+// openBenchmarkCompare opens the comparison of a package's latest benchmark run
+// against its previous run and saved snapshots
+func openBenchmarkCompare(m *model, packageName string) {
+	state := BenchmarkCompareState{
+		packageName: packageName,
+		returnTo:    m.currentScreen,
+	}
+	if previous, exists := m.benchmarkPrevious[packageName]; exists {
+		state.baselines = append(state.baselines, benchmarkBaseline{
+			label:  "previous run " + previous.Started.Format("2006-01-02 15:04:05"),
+			result: previous,
+		})
+	}
+	if pkg, found := findTestPackage(m.testPackages, packageName); found {
+		for _, snapshot := range loadBenchmarkSnapshots(benchmarkSnapshotDir(m.configPath, pkg.Path)) {
+			state.baselines = append(state.baselines, benchmarkBaseline{
+				label:  "snapshot " + snapshot.Saved.Format("2006-01-02 15:04:05"),
+				result: snapshot.Result,
+			})
+		}
+	}
+	m.benchmarkCompare = state
+	m.currentScreen = screenBenchmarkCompare
+}
+
+// handleBenchmarkCompareKeys handles the benchmark comparison view
+func handleBenchmarkCompareKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive || m.currentScreen != screenBenchmarkCompare {
+		return false, nil
+	}
+	state := &m.benchmarkCompare
+	cand, exists := m.benchmarkResults[state.packageName]
+	if !exists {
+		return false, nil
+	}
+	units := len(cand.Units())
+	rows := 0
+	if len(state.baselines) > 0 && units > 0 {
+		base := state.baselines[state.baseline].result
+		rows = len(compareBenchmarks(base, cand, cand.Units()[Clamp(state.column, 0, units-1)]))
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < rows-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = Max(rows-1, 0)
+	case "left", "h":
+		if state.column > 0 {
+			state.column--
+		}
+	case "right", "l":
+		if state.column < units-1 {
+			state.column++
+		}
+	case "n":
+		if state.baseline < len(state.baselines)-1 {
+			state.baseline++
+		}
+	case "p":
+		if state.baseline > 0 {
+			state.baseline--
+		}
+	default:
+		return false, nil
+	}
+
+	// Keep the selected row visible
+	content, cursorLine := FormatBenchmarkComparison(cand, m.currentTheme, *state, m.width-6)
+	visibleLines := m.height - MenuBarH - 2
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
+
+// handleBenchmarkKeys handles the benchmark view
+// "b" on the main screen opens it for the selected package
+func handleBenchmarkKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenBenchmarks {
+		if m.currentScreen != screenMain || msg.String() != "b" || m.selectedIndex >= len(m.testPackages) {
+			return false, nil
+		}
+		return true, openBenchmarks(m, m.testPackages[m.selectedIndex], false)
+	}
+
+	state := &m.benchmarkView
+	state.notice = ""
+	switch msg.String() {
+	case "r":
+		// Run the benchmarks again
+		if pkg, found := findTestPackage(m.testPackages, state.packageName); found {
+			return true, openBenchmarks(m, pkg, true)
+		}
+		return true, nil
+	case "x":
+		m.cancelPackageTests(state.packageName)
+		return true, nil
+	case "c":
+		openBenchmarkCompare(m, state.packageName)
+		return true, nil
+	case "S":
+		// Save the last finished run as a snapshot to compare against later
+		result, exists := m.benchmarkResults[state.packageName]
+		pkg, found := findTestPackage(m.testPackages, state.packageName)
+		if !exists || !found || len(result.Benchmarks) == 0 {
+			state.notice = "No finished benchmark run to save"
+			return true, nil
+		}
+		path, err := saveBenchmarkSnapshot(benchmarkSnapshotDir(m.configPath, pkg.Path), result)
+		if err != nil {
+			LogWarn("Failed to save benchmark snapshot", "package", state.packageName, "error", err)
+			state.notice = "Failed to save snapshot: " + err.Error()
+			return true, nil
+		}
+		LogInfo("Benchmark snapshot saved", "package", state.packageName, "path", path)
+		state.notice = "Saved snapshot " + filepath.Base(path)
+		return true, nil
+	}
+
+	result, exists := m.benchmarkViewResult(state.packageName)
+	if !exists {
+		return false, nil
+	}
+	rows := len(result.Benchmarks)
+	units := len(result.Units())
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < rows-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = Max(rows-1, 0)
+	case "left", "h":
+		if state.column > 0 {
+			state.column--
+		}
+	case "right", "l":
+		if state.column < units-1 {
+			state.column++
+		}
+	case "s":
+		// Run order → ascending → descending
+		state.order = (state.order + 1) % 3
+	default:
+		return false, nil
+	}
+
+	// Keep the selected row visible
+	content, cursorLine := FormatBenchmarks(result, m.currentTheme, *state, m.width-6)
+	visibleLines := m.height - MenuBarH - 2
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleBuildErrorKeys handles the build error view
+// "B" in a test details view opens it when the package failed to build
+func handleBuildErrorKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenBuildErrors {
+		if msg.String() != "B" {
+			return false, nil
+		}
+		pkg, ok := testSelectionPackage(m)
+		if !ok || len(m.testResults[pkg.Name].BuildErrors) == 0 {
+			return false, nil
+		}
+		m.buildErrorView = newBuildErrorViewState(pkg.Name, m.currentScreen)
+		m.currentScreen = screenBuildErrors
+		return true, nil
+	}
+
+	result, exists := m.testResults[m.buildErrorView.packageName]
+	if !exists || len(result.BuildErrors) == 0 {
+		return false, nil
+	}
+	state := &m.buildErrorView
+	count := len(result.BuildErrors)
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < count-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = count - 1
+	default:
+		return false, nil
+	}
+
+	// Keep the selected error visible
+	content, cursorLine := FormatBuildErrors(result.BuildErrors, m.currentTheme, *state)
+	visibleLines := m.height - MenuBarH - 2
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
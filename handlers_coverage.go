@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleCoverageKeys handles the coverage view
+// "C" in a test details view opens the package's per-file coverage list; Enter
+// opens the selected file's source, where n/p jump between uncovered blocks
+func handleCoverageKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenCoverage {
+		if msg.String() != "C" {
+			return false, nil
+		}
+		pkg, ok := testSelectionPackage(m)
+		if !ok || len(m.testResults[pkg.Name].FileCoverages) == 0 {
+			return false, nil
+		}
+		m.coverageView = CoverageViewState{
+			packageName: pkg.Name,
+			packageDir:  pkg.Path,
+			block:       -1,
+			returnTo:    m.currentScreen,
+		}
+		m.currentScreen = screenCoverage
+		return true, nil
+	}
+
+	result, exists := m.testResults[m.coverageView.packageName]
+	if !exists {
+		return false, nil
+	}
+	state := &m.coverageView
+	visibleLines := m.height - MenuBarH - 2
+
+	if state.source == nil {
+		count := len(result.FileCoverages)
+		switch msg.String() {
+		case "up", "k":
+			if state.selected > 0 {
+				state.selected--
+			}
+		case "down", "j":
+			if state.selected < count-1 {
+				state.selected++
+			}
+		case "g":
+			state.selected = 0
+		case "G":
+			state.selected = Max(count-1, 0)
+		case "enter":
+			if state.selected < count {
+				state.source = openCoverageSource(state.packageDir, result.FileCoverages[state.selected])
+				state.scroll = 0
+				state.block = -1
+			}
+			return true, nil
+		default:
+			return false, nil
+		}
+
+		// Keep the selected file visible
+		content, cursorLine := FormatCoverageFiles(result, m.currentTheme, *state)
+		state.listScroll = scrollToLine(state.listScroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+		return true, nil
+	}
+
+	content, _ := FormatCoverageSource(state.source, m.currentTheme, *state, m.width-6)
+	maxScroll := Max(len(strings.Split(content, "\n"))-visibleLines, 0)
+	blocks := len(state.source.blocks)
+
+	switch msg.String() {
+	case "up", "k":
+		state.scroll--
+	case "down", "j":
+		state.scroll++
+	case "pgup", "ctrl+u":
+		state.scroll -= visibleLines
+	case "pgdown", "ctrl+d":
+		state.scroll += visibleLines
+	case "g":
+		state.scroll = 0
+	case "G":
+		state.scroll = maxScroll
+	case "n", "p":
+		if blocks == 0 {
+			return true, nil
+		}
+		if msg.String() == "n" {
+			state.block = Min(state.block+1, blocks-1)
+		} else {
+			state.block = Max(state.block-1, 0)
+		}
+		_, blockLine := FormatCoverageSource(state.source, m.currentTheme, *state, m.width-6)
+		// A few lines of context above the block
+		state.scroll = blockLine - 3
+	default:
+		return false, nil
+	}
+	state.scroll = Clamp(state.scroll, 0, maxScroll)
+	return true, nil
+}
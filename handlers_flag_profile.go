@@ -0,0 +1,115 @@
+package main
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openFlagProfileEditor opens the flag profile editor for the selected package's directory
+func openFlagProfileEditor(m *model) {
+	if m.selectedIndex < 0 || m.selectedIndex >= len(m.testPackages) {
+		return
+	}
+	pkg := m.testPackages[m.selectedIndex]
+	absPath, err := filepath.Abs(pkg.Path)
+	if err != nil {
+		absPath = pkg.Path
+	}
+	m.flagProfileEditor = newFlagProfileEditorState(absPath, pkg.Name, m.config.FlagProfileByDir[absPath])
+	m.currentScreen = screenFlagProfileEditor
+}
+
+// saveFlagProfile stores the edited profile in the config and saves it
+func saveFlagProfile(m *model) {
+	editor := &m.flagProfileEditor
+	if editor.profile.IsEmpty() {
+		delete(m.config.FlagProfileByDir, editor.dirPath)
+	} else {
+		m.config.FlagProfileByDir[editor.dirPath] = editor.profile
+	}
+	if err := SaveConfig(m.config, m.configPath); err != nil {
+		LogWarn("Failed to save flag profile config", "error", err)
+	} else {
+		LogInfo("Flag profile saved", "directory", editor.dirPath, "profile", editor.profile.String())
+	}
+}
+
+// handleFlagProfileInput handles text input while a flag profile field is being edited
+// Returns true if the input was consumed
+func handleFlagProfileInput(m *model, msg tea.KeyMsg) bool {
+	editor := &m.flagProfileEditor
+	if m.currentScreen != screenFlagProfileEditor || !editor.editing {
+		return false
+	}
+
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if len(editor.input) > 0 {
+			runes := []rune(editor.input)
+			editor.input = string(runes[:len(runes)-1])
+		}
+		return true
+
+	case tea.KeyCtrlU:
+		// Clear entire input
+		editor.input = ""
+		return true
+
+	case tea.KeyEnter:
+		if editor.ApplyInput() {
+			saveFlagProfile(m)
+		}
+		return true
+
+	case tea.KeyEsc:
+		editor.CancelEditing()
+		return true
+
+	case tea.KeySpace:
+		editor.input += " "
+		return true
+
+	case tea.KeyRunes:
+		editor.input += string(msg.Runes)
+		return true
+	}
+
+	return false
+}
+
+// handleFlagProfileEditorKeys handles keys for the flag profile editor screen
+func handleFlagProfileEditorKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.currentScreen != screenFlagProfileEditor {
+		return false, nil
+	}
+	editor := &m.flagProfileEditor
+
+	switch msg.String() {
+	case "up", "k":
+		if editor.selectedField > 0 {
+			editor.selectedField--
+		}
+		return true, nil
+
+	case "down", "j":
+		if editor.selectedField < len(editor.fields)-1 {
+			editor.selectedField++
+		}
+		return true, nil
+
+	case "enter":
+		if editor.StartEditing() {
+			saveFlagProfile(m)
+		}
+		return true, nil
+
+	case "d":
+		// Reset the selected field
+		editor.ClearSelectedField()
+		saveFlagProfile(m)
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fuzzViewResults returns the result of each fuzz target of a package: the live
+// result of the target being fuzzed, the last finished run of the others
+func (m *model) fuzzViewResults(pkg TestPackage) map[string]*FuzzResult {
+	results := make(map[string]*FuzzResult)
+	for _, target := range pkg.FuzzTargets() {
+		if result, exists := m.fuzzResults[fuzzKey(pkg.Name, target)]; exists {
+			results[target] = result
+		}
+	}
+	if parser, running := m.liveFuzz[pkg.Name]; running {
+		results[parser.result.Target] = parser.result
+	}
+	return results
+}
+
+// openFuzz opens the fuzz view for a package
+func openFuzz(m *model, pkg TestPackage) {
+	if m.fuzzView.packageName != pkg.Name || m.currentScreen != screenFuzz {
+		returnTo := m.currentScreen
+		if returnTo == screenTestsMenu {
+			returnTo = screenMain
+		}
+		m.fuzzView = newFuzzViewState(pkg.Name, m.config.FuzzTime, returnTo)
+	}
+	m.currentScreen = screenFuzz
+}
+
+// rerunFuzzCrasher runs the failing input of a fuzz target as a regular test and
+// shows the outcome in the package's test details
+func rerunFuzzCrasher(m *model, pkg TestPackage, crasher *FuzzCrasher) tea.Cmd {
+	if crasher == nil || crasher.RunName == "" || m.packageBusy(pkg.Name) {
+		return nil
+	}
+	for i, candidate := range m.testPackages {
+		if candidate.Name == pkg.Name {
+			m.selectedIndex = i
+		}
+	}
+	m.currentScreen = screenMain
+	m.currentFocus = focusRightPanel
+	m.rightPanelView = viewDetails
+	m.rightPanelScroll = 0
+	m.selectedTestName = crasher.RunName
+
+	LogInfo("Rerunning fuzz input as a test", "package", pkg.Name, "test", crasher.RunName)
+	return m.startPackageTests(pkg, testRunOptions{runPattern: buildRunPattern([]string{crasher.RunName})})
+}
+
+// handleFuzzKeys handles the fuzz view
+// "z" on the main screen opens it for the selected package
+func handleFuzzKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenFuzz {
+		if m.currentScreen != screenMain || msg.String() != "z" || m.selectedIndex >= len(m.testPackages) {
+			return false, nil
+		}
+		openFuzz(m, m.testPackages[m.selectedIndex])
+		return true, nil
+	}
+
+	state := &m.fuzzView
+	pkg, found := findTestPackage(m.testPackages, state.packageName)
+	if !found {
+		return false, nil
+	}
+	targets := pkg.FuzzTargets()
+	results := m.fuzzViewResults(pkg)
+	state.notice = ""
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < len(targets)-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = Max(len(targets)-1, 0)
+	case "left", "h":
+		state.duration = nextFuzzDuration(state.duration, -1)
+	case "right", "l":
+		state.duration = nextFuzzDuration(state.duration, 1)
+	case "enter":
+		if len(targets) == 0 {
+			return true, nil
+		}
+		if m.packageBusy(pkg.Name) {
+			state.notice = pkg.Name + " is busy; cancel it with x first"
+			return true, nil
+		}
+		target := targets[Clamp(state.selected, 0, len(targets)-1)]
+		LogInfo("Fuzzing target", "package", pkg.Name, "target", target, "fuzztime", state.duration.String())
+		return true, m.startPackageFuzz(pkg, target, state.duration)
+	case "x":
+		m.cancelPackageTests(pkg.Name)
+		return true, nil
+	case "pgdown", "pgup":
+		// Page through the details of the selected target
+		content, _ := FormatFuzz(pkg.Name, targets, results, m.currentTheme, *state, m.width-6)
+		visibleLines := m.height - MenuBarH - 2
+		maxScroll := Max(len(strings.Split(content, "\n"))-visibleLines, 0)
+		if msg.String() == "pgdown" {
+			state.scroll = Min(state.scroll+visibleLines, maxScroll)
+		} else {
+			state.scroll = Max(state.scroll-visibleLines, 0)
+		}
+		return true, nil
+	case "t":
+		// Rerun the failing input of the selected target as a regular test
+		if len(targets) == 0 {
+			return true, nil
+		}
+		result, exists := results[targets[Clamp(state.selected, 0, len(targets)-1)]]
+		if !exists || result.Crasher == nil || result.Crasher.RunName == "" {
+			state.notice = "No failing input to rerun"
+			return true, nil
+		}
+		if m.packageBusy(pkg.Name) {
+			state.notice = pkg.Name + " is busy; cancel it with x first"
+			return true, nil
+		}
+		return true, rerunFuzzCrasher(m, pkg, result.Crasher)
+	default:
+		return false, nil
+	}
+
+	// Keep the selected target visible
+	content, cursorLine := FormatFuzz(pkg.Name, targets, results, m.currentTheme, *state, m.width-6)
+	visibleLines := m.height - MenuBarH - 2
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
@@ -10,6 +10,11 @@ import (
 // handleTextInput handles text input for theme save mode and hex input mode
 // Returns true if the input was consumed
 func handleTextInput(m *model, msg tea.KeyMsg) bool {
+	// Handle flag profile field input
+	if handleFlagProfileInput(m, msg) {
+		return true
+	}
+
 	if m.currentScreen != screenThemeEditor {
 		return false
 	}
@@ -227,6 +232,42 @@ func handleGlobalKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
 			m.menuActive = false
 		} else if m.currentScreen == screenTestModeSelection {
 			m.currentScreen = screenTestsMenu
+		} else if m.currentScreen == screenFlagProfileEditor {
+			m.currentScreen = screenTestsMenu
+		} else if m.currentScreen == screenGoroutineDump {
+			// Return to the test details the dump was opened from
+			m.currentScreen = m.goroutineDump.returnTo
+		} else if m.currentScreen == screenRaceReport {
+			// Return to the test details the race view was opened from
+			m.currentScreen = m.raceReport.returnTo
+		} else if m.currentScreen == screenPanicStack {
+			// Return to the test details the panic was opened from
+			m.currentScreen = m.panicStack.returnTo
+		} else if m.currentScreen == screenBuildErrors {
+			// Return to the test details the build errors were opened from
+			m.currentScreen = m.buildErrorView.returnTo
+		} else if m.currentScreen == screenBenchmarks {
+			// Benchmarks keep running in the background
+			m.currentScreen = m.benchmarkView.returnTo
+		} else if m.currentScreen == screenBenchmarkCompare {
+			// Return to the benchmark view the comparison was opened from
+			m.currentScreen = m.benchmarkCompare.returnTo
+		} else if m.currentScreen == screenFuzz {
+			// Fuzzing keeps running in the background
+			m.currentScreen = m.fuzzView.returnTo
+		} else if m.currentScreen == screenProfile {
+			// Return to the test details the profile was opened from
+			m.currentScreen = m.profileView.returnTo
+		} else if m.currentScreen == screenTimeline {
+			// Return to the test details the timeline was opened from
+			m.currentScreen = m.timelineView.returnTo
+		} else if m.currentScreen == screenCoverage {
+			// Close the open file first, then return to the test details
+			if m.coverageView.source != nil {
+				m.coverageView.source = nil
+			} else {
+				m.currentScreen = m.coverageView.returnTo
+			}
 		} else if m.currentScreen == screenFullTestResults {
 			// Return from full-screen test results to main
 			m.currentScreen = screenMain
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleGoroutineDumpKeys handles the goroutine dump browser
+// "d" in a test details view opens it when the package's run timed out
+func handleGoroutineDumpKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenGoroutineDump {
+		if msg.String() != "d" {
+			return false, nil
+		}
+		pkg, ok := testSelectionPackage(m)
+		if !ok || m.testResults[pkg.Name].Timeout == nil {
+			return false, nil
+		}
+		m.goroutineDump = newGoroutineDumpState(pkg.Name, m.currentScreen)
+		m.currentScreen = screenGoroutineDump
+		return true, nil
+	}
+
+	result, exists := m.testResults[m.goroutineDump.packageName]
+	if !exists || result.Timeout == nil {
+		return false, nil
+	}
+	state := &m.goroutineDump
+	count := len(result.Timeout.Goroutines)
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < count-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = count - 1
+	case "enter", " ":
+		// Expand or collapse the selected goroutine
+		state.expanded[state.selected] = !state.expanded[state.selected]
+	case "e":
+		// Expand all
+		for i := 0; i < count; i++ {
+			state.expanded[i] = true
+		}
+	case "c":
+		// Collapse all
+		state.expanded = make(map[int]bool)
+	default:
+		return false, nil
+	}
+
+	// Keep the selected goroutine visible
+	content, cursorLine := FormatGoroutineDump(result.Timeout, m.currentTheme, *state)
+	visibleLines := m.height - MenuBarH - 2
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
@@ -26,18 +26,15 @@ func handleMainScreenKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
 			// Run tests for the selected package
 			if m.selectedIndex < len(m.testPackages) {
 				pkg := m.testPackages[m.selectedIndex]
-				// Clear old results and errors for this package
-				delete(m.testResults, pkg.Name)
-				delete(m.testErrors, pkg.Name)
-				// Mark test as running
-				m.testsRunning[pkg.Name] = true
+				// Ignore if this package is already running
+				if m.packageBusy(pkg.Name) {
+					return true, nil
+				}
 				// Reset view state when running a new test
 				m.rightPanelView = viewSummary
 				m.summaryButtonIndex = 0
 				m.rightPanelScroll = 0
-				// Use test mode for this specific package's directory
-				pkgMode := m.getTestModeForPath(pkg.Path)
-				return true, runTestsCmd(pkg.Path, pkg.Name, pkgMode)
+				return true, m.startPackageTests(pkg, testRunOptions{})
 			}
 		} else if m.currentFocus == focusRightPanel && m.rightPanelView == viewSummary {
 			// User pressed Enter on a button - navigate based on which button
@@ -53,6 +50,24 @@ func handleMainScreenKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
 		}
 		return true, nil
 
+	case "x":
+		// Cancel the selected package's running tests
+		if m.selectedIndex < len(m.testPackages) {
+			name := m.testPackages[m.selectedIndex].Name
+			if m.batchRuns[name] {
+				// Cancelling would stop every package of the shared go test
+				m.statusNotice = name + " runs in a single go test with other packages - X cancels them all"
+				return true, nil
+			}
+			m.cancelPackageTests(name)
+		}
+		return true, nil
+
+	case "X":
+		// Cancel everything: drain the "Run All" queue and stop running packages
+		m.cancelAllTests()
+		return true, nil
+
 	case "f":
 		// Show full-screen mode based on which button is selected
 		if m.selectedIndex < len(m.testPackages) {
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handlePanicStackKeys handles the panic stack view
+// "P" in a test details view opens it when a test of the package panicked
+func handlePanicStackKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenPanicStack {
+		if msg.String() != "P" {
+			return false, nil
+		}
+		pkg, ok := testSelectionPackage(m)
+		if !ok || m.testResults[pkg.Name].Panic == nil {
+			return false, nil
+		}
+		m.panicStack = newPanicStackState(pkg.Name, m.testResults[pkg.Name].Panic, m.modulePath, m.currentScreen)
+		m.currentScreen = screenPanicStack
+		return true, nil
+	}
+
+	result, exists := m.testResults[m.panicStack.packageName]
+	if !exists || result.Panic == nil {
+		return false, nil
+	}
+	state := &m.panicStack
+	rows := panicStackRows(result.Panic.Goroutine.Frames, state.expanded)
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < len(rows)-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = len(rows) - 1
+	case "enter", " ":
+		// Expand or fold the runtime/testing group under the cursor
+		if state.selected < len(rows) && rows[state.selected].group >= 0 {
+			group := rows[state.selected].group
+			state.expanded[group] = !state.expanded[group]
+			// Keep the cursor on the group's first row
+			for i, row := range panicStackRows(result.Panic.Goroutine.Frames, state.expanded) {
+				if row.frame == group {
+					state.selected = i
+				}
+			}
+		}
+	case "e":
+		// Expand or fold every group
+		expand := true
+		for _, shown := range state.expanded {
+			if shown {
+				expand = false
+			}
+		}
+		state.expanded = make(map[int]bool)
+		if expand {
+			for _, row := range rows {
+				if row.group >= 0 {
+					state.expanded[row.group] = true
+				}
+			}
+		}
+		state.selected = Clamp(state.selected, 0, len(panicStackRows(result.Panic.Goroutine.Frames, state.expanded))-1)
+	default:
+		return false, nil
+	}
+
+	// Keep the selected row visible
+	content, cursorLine := FormatPanicStack(result.Panic, m.modulePath, m.currentTheme, *state)
+	visibleLines := m.height - MenuBarH - 2
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
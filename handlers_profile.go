@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startProfilingRun runs a package's tests with -cpuprofile and -memprofile
+func startProfilingRun(m *model, pkg TestPackage) tea.Cmd {
+	if m.packageBusy(pkg.Name) {
+		return nil
+	}
+	m.rightPanelView = viewSummary
+	m.summaryButtonIndex = 0
+	m.rightPanelScroll = 0
+	dir := profileDir(pkg.Path)
+	LogInfo("Profiling package tests", "package", pkg.Name, "directory", dir)
+	return m.startPackageTests(pkg, testRunOptions{profileDir: dir})
+}
+
+// handleProfileKeys handles the profile view
+// "T" in a test details view opens it when the package's last run was profiled
+func handleProfileKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenProfile {
+		if msg.String() != "T" {
+			return false, nil
+		}
+		pkg, ok := testSelectionPackage(m)
+		if !ok {
+			return false, nil
+		}
+		result := m.testResults[pkg.Name]
+		if result.CPUProfile == "" && result.MemProfile == "" {
+			return false, nil
+		}
+		m.profileView = newProfileViewState(result, m.currentScreen)
+		m.currentScreen = screenProfile
+		return true, nil
+	}
+
+	result, exists := m.testResults[m.profileView.packageName]
+	if !exists {
+		return false, nil
+	}
+	state := &m.profileView
+	profile, _, _ := state.shown(result)
+	rows, sampleTypes := 0, 0
+	if profile != nil {
+		sampleTypes = len(profile.SampleTypes)
+		functions, _ := profile.TopFunctions(Clamp(state.sampleIndex, 0, Max(sampleTypes-1, 0)), state.byCum)
+		rows = Min(len(functions), profileTopN)
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < rows-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = Max(rows-1, 0)
+	case "left", "h":
+		if state.sampleIndex > 0 {
+			state.sampleIndex--
+			state.selected = 0
+		}
+	case "right", "l":
+		if state.sampleIndex < sampleTypes-1 {
+			state.sampleIndex++
+			state.selected = 0
+		}
+	case "c", "m":
+		memory := msg.String() == "m"
+		if state.memory != memory {
+			state.memory = memory
+			state.sampleIndex = state.defaultSampleIndex()
+			state.selected = 0
+		}
+	case "s":
+		// Flat ↔ cumulative
+		state.byCum = !state.byCum
+		state.selected = 0
+	default:
+		return false, nil
+	}
+
+	// Keep the selected row visible
+	content, cursorLine := FormatProfile(result, m.currentTheme, *state, m.width-6)
+	visibleLines := m.height - MenuBarH - 2
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleRaceReportKeys handles the data race view
+// "D" in a test details view opens it when the package's run reported races
+func handleRaceReportKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenRaceReport {
+		if msg.String() != "D" {
+			return false, nil
+		}
+		pkg, ok := testSelectionPackage(m)
+		if !ok || len(m.testResults[pkg.Name].Races) == 0 {
+			return false, nil
+		}
+		m.raceReport = newRaceReportState(pkg.Name, m.currentScreen)
+		m.currentScreen = screenRaceReport
+		return true, nil
+	}
+
+	result, exists := m.testResults[m.raceReport.packageName]
+	if !exists || len(result.Races) == 0 {
+		return false, nil
+	}
+	state := &m.raceReport
+	count := len(result.Races)
+
+	content, _ := FormatRaceReport(result.Races, m.currentTheme, *state, m.width-6)
+	totalLines := len(strings.Split(content, "\n"))
+	visibleLines := m.height - MenuBarH - 2
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < count-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = count - 1
+	case "pgup":
+		state.scroll = Clamp(state.scroll-visibleLines, 0, Max(totalLines-visibleLines, 0))
+		return true, nil
+	case "pgdown":
+		state.scroll = Clamp(state.scroll+visibleLines, 0, Max(totalLines-visibleLines, 0))
+		return true, nil
+	default:
+		return false, nil
+	}
+
+	// Keep the selected race visible
+	content, cursorLine := FormatRaceReport(result.Races, m.currentTheme, *state, m.width-6)
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
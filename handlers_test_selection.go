@@ -0,0 +1,239 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testSelectionPackage returns the package whose test list is on screen
+// Returns false when no finished result is being shown in a details view
+func testSelectionPackage(m *model) (TestPackage, bool) {
+	var name string
+	switch {
+	case m.currentScreen == screenFullTestResults:
+		name = m.fullScreenPackage
+	case m.currentScreen == screenMain && m.currentFocus == focusRightPanel && m.rightPanelView == viewDetails:
+		if m.selectedIndex >= len(m.testPackages) {
+			return TestPackage{}, false
+		}
+		name = m.testPackages[m.selectedIndex].Name
+	default:
+		return TestPackage{}, false
+	}
+
+	if _, exists := m.testResults[name]; !exists {
+		return TestPackage{}, false
+	}
+	for _, pkg := range m.testPackages {
+		if pkg.Name == name {
+			return pkg, true
+		}
+	}
+	return TestPackage{}, false
+}
+
+// handleTestSelectionKeys handles the test cursor in the test details views
+// n/p move the cursor, space expands or collapses the selected test's subtests and
+// e all of them, r runs the selected test with its subtests, R without them,
+// s reruns the package in the shuffled order of its last run
+func handleTestSelectionKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+	pkg, ok := testSelectionPackage(m)
+	if !ok {
+		return false, nil
+	}
+
+	switch msg.String() {
+	case "n":
+		moveTestCursor(m, pkg, 1)
+		return true, nil
+
+	case "p":
+		moveTestCursor(m, pkg, -1)
+		return true, nil
+
+	case " ":
+		toggleSelectedSubtests(m, pkg)
+		return true, nil
+
+	case "e":
+		toggleAllSubtests(m, pkg)
+		return true, nil
+
+	case "r":
+		return true, runSelectedTest(m, pkg, true)
+
+	case "R":
+		return true, runSelectedTest(m, pkg, false)
+
+	case "s":
+		return true, replayShuffleSeed(m, pkg)
+	}
+
+	return false, nil
+}
+
+// moveTestCursor moves the test cursor by delta and scrolls the cursor into view
+func moveTestCursor(m *model, pkg TestPackage, delta int) {
+	result := m.testResults[pkg.Name]
+	tests := selectableTests(result, m.expandedTests[pkg.Name])
+	if len(tests) == 0 {
+		return
+	}
+
+	// Start from the first test if nothing in this package is selected yet
+	current := -1
+	for i, test := range tests {
+		if test.Name == m.selectedTestName {
+			current = i
+			break
+		}
+	}
+	next := current + delta
+	if current < 0 {
+		next = 0
+	}
+	if next < 0 {
+		next = 0
+	}
+	if next >= len(tests) {
+		next = len(tests) - 1
+	}
+	m.selectedTestName = tests[next].Name
+	scrollToTestCursor(m, pkg)
+}
+
+// toggleSelectedSubtests expands or collapses the subtests of the test under the cursor
+// On a test without subtests, its parent is collapsed and selected instead
+func toggleSelectedSubtests(m *model, pkg TestPackage) {
+	node := findTestNode(m.testResults[pkg.Name].Tests, m.selectedTestName)
+	if node == nil {
+		return
+	}
+	expanded := testTreeToggles(m, pkg)
+	if len(node.children) > 0 {
+		expanded[node.test.Name] = !isTestExpanded(node, expanded)
+	} else if ancestors := testAncestors(node.test.Name); len(ancestors) > 0 {
+		expanded[ancestors[0]] = false
+		m.selectedTestName = ancestors[0]
+	}
+	scrollToTestCursor(m, pkg)
+}
+
+// toggleAllSubtests expands every parent in the subtest tree, or collapses them
+// all if they are all expanded already
+// A cursor on a subtest that gets hidden moves to its top-level test
+func toggleAllSubtests(m *model, pkg TestPackage) {
+	parents := testTreeParents(m.testResults[pkg.Name].Tests)
+	if len(parents) == 0 {
+		return
+	}
+	expanded := testTreeToggles(m, pkg)
+	open := false
+	for _, parent := range parents {
+		if !isTestExpanded(parent, expanded) {
+			open = true
+			break
+		}
+	}
+	for _, parent := range parents {
+		expanded[parent.test.Name] = open
+	}
+	if ancestors := testAncestors(m.selectedTestName); !open && len(ancestors) > 0 {
+		m.selectedTestName = ancestors[len(ancestors)-1]
+	}
+	scrollToTestCursor(m, pkg)
+}
+
+// testTreeToggles returns the subtest tree toggles of a package, creating them if needed
+func testTreeToggles(m *model, pkg TestPackage) map[string]bool {
+	expanded, exists := m.expandedTests[pkg.Name]
+	if !exists {
+		expanded = make(map[string]bool)
+		m.expandedTests[pkg.Name] = expanded
+	}
+	return expanded
+}
+
+// scrollToTestCursor scrolls the details view so the test cursor row is visible
+func scrollToTestCursor(m *model, pkg TestPackage) {
+	result := m.testResults[pkg.Name]
+	content := FormatTestResult(result, m.modulePath, m.currentTheme, m.selectedTestName, m.expandedTests[pkg.Name])
+	line := testCursorLine(content)
+	if line < 0 {
+		return
+	}
+	totalLines := len(strings.Split(content, "\n"))
+	if m.currentScreen == screenFullTestResults {
+		visibleLines := m.height - MenuBarH - 2
+		m.fullScreenScroll = scrollToLine(m.fullScreenScroll, line, visibleLines, totalLines)
+	} else {
+		frame := Frame{Width: m.width, Height: m.height}
+		split := NewSplit(frame, m.leftPanelWidth, m.config.MinPanelWidth, (m.width*m.config.MaxPanelWidthPercent)/100)
+		visibleLines := split.ContentH - 2
+		m.rightPanelScroll = scrollToLine(m.rightPanelScroll, line, visibleLines, totalLines)
+	}
+}
+
+// scrollToLine returns the scroll offset that keeps line inside the visible window
+// A few lines below the cursor stay visible so the selected test's output shows
+func scrollToLine(scroll, line, visibleLines, totalLines int) int {
+	if visibleLines < 1 {
+		return scroll
+	}
+	const lookahead = 3
+	if line < scroll {
+		scroll = line
+	} else if line+lookahead >= scroll+visibleLines {
+		scroll = line + lookahead - visibleLines + 1
+	}
+	maxScroll := totalLines - visibleLines
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if scroll > maxScroll {
+		scroll = maxScroll
+	}
+	if scroll < 0 {
+		scroll = 0
+	}
+	return scroll
+}
+
+// runSelectedTest reruns the test under the cursor with an anchored -run pattern
+// The outcome is merged into the package's previous result when it finishes
+// With withSubtests false, only the test itself runs and its subtests are skipped
+func runSelectedTest(m *model, pkg TestPackage, withSubtests bool) tea.Cmd {
+	if m.selectedTestName == "" || m.packageBusy(pkg.Name) {
+		return nil
+	}
+
+	pattern := buildRunPattern([]string{m.selectedTestName})
+	if !withSubtests {
+		// An empty-only pattern for the next level matches no subtest name
+		pattern += "/^$"
+	}
+
+	LogInfo("Running selected test",
+		"package", pkg.Name,
+		"test", m.selectedTestName,
+		"subtests", withSubtests,
+	)
+	return m.startPackageTests(pkg, testRunOptions{runPattern: pattern})
+}
+
+// replayShuffleSeed reruns every test of a package with the -shuffle seed of its
+// last run, reproducing the order the tests ran in
+// Returns nil if the last run wasn't shuffled
+func replayShuffleSeed(m *model, pkg TestPackage) tea.Cmd {
+	result := m.testResults[pkg.Name]
+	if result.ShuffleSeed == "" || m.packageBusy(pkg.Name) {
+		return nil
+	}
+
+	LogInfo("Replaying shuffled test order", "package", pkg.Name, "seed", result.ShuffleSeed)
+	return m.startPackageTests(pkg, testRunOptions{shuffle: result.ShuffleSeed})
+}
@@ -29,46 +29,98 @@ func handleTestsMenuKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
 		switch m.testsMenuIndex {
 		case 0: // Know It All
 			m.currentScreen = screenMain
-			// Run tests for all packages SEQUENTIALLY
+			// Run tests for all packages, several at a time
 			// Reset view state when running tests
 			m.rightPanelView = viewSummary
 			m.summaryButtonIndex = 0
 			m.rightPanelScroll = 0
-
-			// Initialize test queue with all packages
-			m.testQueue = make([]TestPackage, len(m.testPackages))
-			copy(m.testQueue, m.testPackages)
-			m.runAllInProgress = true
-
-			// Clear old results and start first test
-			if len(m.testQueue) > 0 {
-				pkg := m.testQueue[0]
-				m.testQueue = m.testQueue[1:]
-				// Clear old results and errors
-				delete(m.testResults, pkg.Name)
-				delete(m.testErrors, pkg.Name)
-				// Mark test as running
-				m.testsRunning[pkg.Name] = true
-				// Use test mode for this specific package's directory
-				pkgMode := m.getTestModeForPath(pkg.Path)
-				return true, runTestsCmd(pkg.Path, pkg.Name, pkgMode)
-			}
-			return true, nil
-		case 1: // Test Mode
+			return true, m.startRunAll()
+		case 1: // Rerun Failures
+			m.currentScreen = screenMain
+			m.rightPanelView = viewSummary
+			m.summaryButtonIndex = 0
+			m.rightPanelScroll = 0
+			return true, m.startRerunFailures()
+		case 2: // Test Mode
 			m.currentScreen = screenTestModeSelection
-			// Set index to current mode for the selected package
+			// Offer the modes discovered from the selected package's build tags
+			// and set index to its current mode
 			var pkgMode testMode = testModeUnit // default
+			var pkg TestPackage
 			if m.selectedIndex >= 0 && m.selectedIndex < len(m.testPackages) {
-				pkg := m.testPackages[m.selectedIndex]
+				pkg = m.testPackages[m.selectedIndex]
 				pkgMode = m.getTestModeForPath(pkg.Path)
 			}
-			switch pkgMode {
-			case testModeUnit:
-				m.testModeIndex = 0
-			case testModeIntegration:
-				m.testModeIndex = 1
-			case testModeAll:
-				m.testModeIndex = 2
+			m.testModeItems = testModeOptions(pkg, pkgMode)
+			m.testModeIndex = 0
+			for i, mode := range m.testModeItems {
+				if mode == pkgMode {
+					m.testModeIndex = i
+				}
+			}
+			return true, nil
+		case 3: // Execution Strategy - toggle for the scanned project
+			newStrategy := strategySingle
+			if m.executionStrategy() == strategySingle {
+				newStrategy = strategyPerPackage
+			}
+			absPath, err := filepath.Abs(m.scanPath)
+			if err == nil {
+				m.config.StrategyByDir[absPath] = string(newStrategy)
+				if saveErr := SaveConfig(m.config, m.configPath); saveErr != nil {
+					LogWarn("Failed to save execution strategy config", "error", saveErr)
+				} else {
+					LogInfo("Execution strategy saved", "directory", absPath, "strategy", newStrategy)
+				}
+			}
+			return true, nil
+		case 4: // Flag Profile - edit for the selected package's directory
+			openFlagProfileEditor(m)
+			return true, nil
+		case 5: // Flaky Check - run the selected package's tests repeatedly
+			if m.selectedIndex >= len(m.testPackages) {
+				return true, nil
+			}
+			pkg := m.testPackages[m.selectedIndex]
+			m.currentScreen = screenMain
+			if m.packageBusy(pkg.Name) {
+				return true, nil
+			}
+			m.rightPanelView = viewSummary
+			m.summaryButtonIndex = 0
+			m.rightPanelScroll = 0
+			LogInfo("Checking for flaky tests", "package", pkg.Name, "runs", m.config.FlakyRunCount)
+			return true, m.startPackageTests(pkg, testRunOptions{count: m.config.FlakyRunCount})
+		case 6: // Benchmarks - run the selected package's benchmarks
+			if m.selectedIndex >= len(m.testPackages) {
+				return true, nil
+			}
+			return true, openBenchmarks(m, m.testPackages[m.selectedIndex], true)
+		case 7: // Fuzz - pick a fuzz target of the selected package to fuzz
+			if m.selectedIndex >= len(m.testPackages) {
+				return true, nil
+			}
+			openFuzz(m, m.testPackages[m.selectedIndex])
+			return true, nil
+		case 8: // Profile - run the selected package's tests with CPU and memory profiling
+			if m.selectedIndex >= len(m.testPackages) {
+				return true, nil
+			}
+			m.currentScreen = screenMain
+			return true, startProfilingRun(m, m.testPackages[m.selectedIndex])
+		case 9: // Cross-Package Coverage - toggle -coverpkg for the scanned project
+			absPath, err := filepath.Abs(m.scanPath)
+			if err == nil {
+				if m.coverPkgSetting() == "" {
+					m.config.CoverPkgByDir[absPath] = coverPkgModule
+				} else {
+					delete(m.config.CoverPkgByDir, absPath)
+				}
+				if saveErr := SaveConfig(m.config, m.configPath); saveErr != nil {
+					LogWarn("Failed to save cross-package coverage config", "error", saveErr)
+				} else {
+					LogInfo("Cross-package coverage saved", "directory", absPath, "coverpkg", m.coverPkgSetting())
+				}
 			}
 			return true, nil
 		}
@@ -99,15 +151,7 @@ func handleTestModeSelectionKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
 
 	case "enter":
 		// Set the test mode based on selection
-		var newMode testMode
-		switch m.testModeIndex {
-		case 0:
-			newMode = testModeUnit
-		case 1:
-			newMode = testModeIntegration
-		case 2:
-			newMode = testModeAll
-		}
+		newMode := m.testModeItems[m.testModeIndex]
 
 		// Save test mode to config for the currently selected package's directory
 		if m.selectedIndex >= 0 && m.selectedIndex < len(m.testPackages) {
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleTimelineKeys handles the test timeline view
+// "L" in a test details view opens it for the package's last run
+func handleTimelineKeys(m *model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.menuActive {
+		return false, nil
+	}
+
+	if m.currentScreen != screenTimeline {
+		if msg.String() != "L" {
+			return false, nil
+		}
+		pkg, ok := testSelectionPackage(m)
+		if !ok {
+			return false, nil
+		}
+		m.timelineView = TimelineViewState{packageName: pkg.Name, returnTo: m.currentScreen}
+		m.currentScreen = screenTimeline
+		return true, nil
+	}
+
+	result, exists := m.testResults[m.timelineView.packageName]
+	if !exists {
+		return false, nil
+	}
+	state := &m.timelineView
+	timeline, _ := buildTimeline(result)
+	count := len(timeline.Tests)
+	visibleLines := m.height - MenuBarH - 2
+
+	switch msg.String() {
+	case "up", "k":
+		if state.selected > 0 {
+			state.selected--
+		}
+	case "down", "j":
+		if state.selected < count-1 {
+			state.selected++
+		}
+	case "g":
+		state.selected = 0
+	case "G":
+		state.selected = Max(count-1, 0)
+	case "pgup":
+		state.selected = Max(state.selected-visibleLines, 0)
+	case "pgdown":
+		state.selected = Clamp(state.selected+visibleLines, 0, Max(count-1, 0))
+	default:
+		return false, nil
+	}
+
+	// Keep the selected test visible
+	content, cursorLine := FormatTimeline(result, m.currentTheme, *state, m.width-6)
+	state.scroll = scrollToLine(state.scroll, cursorLine, visibleLines, len(strings.Split(content, "\n")))
+	return true, nil
+}
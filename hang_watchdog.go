@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hangCheckInterval is how often running tests are checked against the hang threshold
+const hangCheckInterval = time.Second
+
+// hangCheckMsg is sent periodically while tests are running
+type hangCheckMsg time.Time
+
+// hangCheckCmd schedules the next hang check
+func hangCheckCmd() tea.Cmd {
+	return tea.Tick(hangCheckInterval, func(t time.Time) tea.Msg {
+		return hangCheckMsg(t)
+	})
+}
+
+// startHangWatch starts the periodic hang check unless it is running or disabled
+func (m *model) startHangWatch() tea.Cmd {
+	if m.hangWatchActive || m.config.HangThresholdSeconds <= 0 {
+		return nil
+	}
+	m.hangWatchActive = true
+	return hangCheckCmd()
+}
+
+// checkForHungTests flags running tests that exceeded the hang threshold
+// Only the innermost running tests are flagged; a parent waiting on a slow
+// subtest is not hung itself
+func (m *model) checkForHungTests(now time.Time) {
+	threshold := time.Duration(m.config.HangThresholdSeconds) * time.Second
+	for packageName, parser := range m.liveTests {
+		tests := parser.result.Tests
+		for i := range tests {
+			test := &tests[i]
+			if test.Status != "RUNNING" || test.Hung || test.Started.IsZero() {
+				continue
+			}
+			if now.Sub(test.Started) < threshold || hasRunningSubtest(tests, test.Name) {
+				continue
+			}
+			test.Hung = true
+			LogWarn("Test may be hung",
+				"package", packageName,
+				"test", test.Name,
+				"running_for", now.Sub(test.Started).Round(time.Second).String(),
+				"threshold", threshold.String(),
+			)
+		}
+	}
+}
+
+// hasRunningSubtest reports whether any subtest of name is still running
+func hasRunningSubtest(tests []TestResult, name string) bool {
+	for _, test := range tests {
+		if test.Status == "RUNNING" && strings.HasPrefix(test.Name, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hungTestCount returns the number of running tests flagged as possibly hung
+func hungTestCount(result *PackageTestResult) int {
+	count := 0
+	for _, test := range result.Tests {
+		if test.Status == "RUNNING" && test.Hung {
+			count++
+		}
+	}
+	return count
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -15,11 +18,15 @@ const version = "0.1.0"
 
 // UI constants
 const (
-	defaultLeftPanelWidth  = 40
-	minPanelWidth          = 15
-	maxPanelWidthPercent   = 80 // 80% of screen width
-	panelResizeIncrement   = 5
-	colorPaletteColumns    = 8
+	defaultLeftPanelWidth       = 40
+	minPanelWidth               = 15
+	maxPanelWidthPercent        = 80 // 80% of screen width
+	panelResizeIncrement        = 5
+	colorPaletteColumns         = 8
+	defaultHangThresholdSeconds = 60
+	defaultFlakyRunCount        = 10
+	defaultBenchmarkCount       = 6 // Fewest samples giving a 95% confidence interval for the median
+	defaultFuzzTime             = 30 * time.Second
 )
 
 type appScreen int
@@ -35,6 +42,17 @@ const (
 	screenHelp
 	screenFullTestResults
 	screenFullCoverageGaps
+	screenFlagProfileEditor
+	screenGoroutineDump
+	screenRaceReport
+	screenPanicStack
+	screenBuildErrors
+	screenBenchmarks
+	screenBenchmarkCompare
+	screenFuzz
+	screenProfile
+	screenTimeline
+	screenCoverage
 )
 
 type testMode string
@@ -65,15 +83,48 @@ type testStartMsg struct {
 	packageName string
 }
 
+// testEventMsg is sent for each go test -json event while a package is running
+type testEventMsg struct {
+	packageName string
+	event       TestEvent
+	stream      <-chan tea.Msg // Stream to keep listening on for further messages
+}
+
 // testCompleteMsg is sent when a test run completes
 type testCompleteMsg struct {
 	result *PackageTestResult
+	stream <-chan tea.Msg // Set when more packages report on the same stream
+}
+
+// benchmarkEventMsg is sent for each go test -json event of a benchmark run
+type benchmarkEventMsg struct {
+	packageName string
+	event       TestEvent
+	stream      <-chan tea.Msg
+}
+
+// benchmarkCompleteMsg is sent when a benchmark run completes
+type benchmarkCompleteMsg struct {
+	result *PackageBenchmarkResult
+}
+
+// fuzzEventMsg is sent for each go test -json event while a target is fuzzed
+type fuzzEventMsg struct {
+	packageName string
+	event       TestEvent
+	stream      <-chan tea.Msg
+}
+
+// fuzzCompleteMsg is sent when fuzzing a target completes
+type fuzzCompleteMsg struct {
+	result *FuzzResult
 }
 
 // testErrorMsg is sent when a test run fails
 type testErrorMsg struct {
 	packageName string
 	err         error
+	stream      <-chan tea.Msg
 }
 
 type themeSelectionMode int
@@ -91,35 +142,36 @@ type model struct {
 	testPackages   []TestPackage
 	selectedIndex  int
 	scanPath       string
+	modulePath     string // Module path of the scanned project's go.mod, "" if not found
 	currentTheme   Theme
 	themeNames     []string
 	themeIndex     int
 
 	// Menu state
-	menuActive     bool
-	menuItems      []string
-	menuIndex      int
-	currentScreen  appScreen
+	menuActive    bool
+	menuItems     []string
+	menuIndex     int
+	currentScreen appScreen
 
 	// Tests menu state
 	testsMenuIndex int
 	testsMenuItems []string
 
 	// Test mode state
-	currentTestMode     testMode
-	testModeIndex       int
-	testModeItems       []string
+	currentTestMode testMode
+	testModeIndex   int
+	testModeItems   []testMode // Modes offered for the selected package
 
 	// Theme menu state
-	themeMenuIndex     int
-	themeMenuItems     []string
+	themeMenuIndex int
+	themeMenuItems []string
 
 	// Theme selection state
-	themeSelectionMode themeSelectionMode
+	themeSelectionMode  themeSelectionMode
 	themeSelectionIndex int
 
 	// Theme editor state
-	themeEditor    ThemeEditorState
+	themeEditor ThemeEditorState
 
 	// Configuration
 	config     Config
@@ -131,11 +183,34 @@ type model struct {
 	testErrors map[string]error
 	// Tests currently running - maps package name to running state
 	testsRunning map[string]bool
-	// Sequential test execution state
-	testQueue        []TestPackage // Packages waiting to be tested
-	runAllInProgress bool          // Whether "Run All" is active
+	// Packages running benchmarks - they hold the package like a test run but
+	// not a parallel test slot
+	benchmarksRunning map[string]bool
+	// Packages being fuzzed, held the same way as benchmarking packages
+	fuzzRunning map[string]bool
+	// Live results for running packages, built up from streamed test events
+	liveTests map[string]*testEventParser
+	// Cancel functions for running packages - maps package name to cancel
+	testCancels map[string]context.CancelFunc
+	// "Run All" execution state - up to parallelLimit() packages run at once
+	testQueue []TestPackage // Packages waiting to be tested
+	// Options for queued packages that only run some tests (e.g. failure reruns)
+	queuedRunOptions map[string]testRunOptions
+	// Packages whose running tests are a partial rerun to merge into the previous result
+	partialRuns map[string]bool
+	// Packages of a single-invocation run - they share one go test and one cancel
+	batchRuns        map[string]bool
+	runAllInProgress bool      // Whether "Run All" is active
+	runAllStarted    time.Time // When the current "Run All" started
+	runAllStrategy   executionStrategy
+	// Whether the periodic hang check is scheduled
+	hangWatchActive bool
+	// Wall-clock time of the last completed "Run All" per strategy, for comparison
+	runAllDurations map[executionStrategy]time.Duration
 	// Scan error - error from initial package scan
 	scanError error
+	// Message shown in the main screen's help bar until the next key press
+	statusNotice string
 
 	// Panel focus and scrolling
 	currentFocus       panelFocus
@@ -149,6 +224,48 @@ type model struct {
 	// Full-screen test results
 	fullScreenPackage string // Package name for full-screen results view
 	fullScreenScroll  int    // Scroll position in full-screen view
+
+	// Test under the cursor in the test details views ("" for none)
+	selectedTestName string
+
+	// Subtest tree parents toggled open (true) or closed (false) per package
+	expandedTests map[string]map[string]bool
+
+	// Flag profile editor state
+	flagProfileEditor FlagProfileEditorState
+
+	// Goroutine dump browser state (timed out runs)
+	goroutineDump GoroutineDumpState
+
+	// Data race view state (-race runs)
+	raceReport RaceReportState
+
+	// Panic stack view state
+	panicStack PanicStackState
+
+	// Build error view state (packages that failed to compile)
+	buildErrorView BuildErrorViewState
+
+	// Benchmark state
+	benchmarkResults  map[string]*PackageBenchmarkResult // Last finished benchmark run per package
+	benchmarkPrevious map[string]*PackageBenchmarkResult // The run before it, the default comparison baseline
+	liveBenchmarks    map[string]*benchmarkParser        // Benchmark runs in progress
+	benchmarkView     BenchmarkViewState
+	benchmarkCompare  BenchmarkCompareState
+
+	// Fuzzing state
+	fuzzResults map[string]*FuzzResult // Last finished run per fuzzKey
+	liveFuzz    map[string]*fuzzParser // Fuzz runs in progress per package
+	fuzzView    FuzzViewState
+
+	// Profile view state (profiling runs)
+	profileView ProfileViewState
+
+	// Test timeline view state
+	timelineView TimelineViewState
+
+	// Coverage view state (per-file list and source)
+	coverageView CoverageViewState
 }
 
 func initialModel(scanPath string, flagConfigPath string) model {
@@ -197,14 +314,12 @@ func initialModel(scanPath string, flagConfigPath string) model {
 	}
 
 	// Set the test mode index based on loaded mode
+	modeItems := []testMode{testModeUnit, testModeIntegration, testModeAll}
 	modeIndex := 0
-	switch currentMode {
-	case testModeUnit:
-		modeIndex = 0
-	case testModeIntegration:
-		modeIndex = 1
-	case testModeAll:
-		modeIndex = 2
+	for i, mode := range modeItems {
+		if mode == currentMode {
+			modeIndex = i
+		}
 	}
 
 	return model{
@@ -212,6 +327,7 @@ func initialModel(scanPath string, flagConfigPath string) model {
 		testPackages:        packages,
 		selectedIndex:       0,
 		scanPath:            scanPath,
+		modulePath:          findModulePath(scanPath),
 		currentTheme:        currentTheme,
 		themeNames:          themeNames,
 		themeIndex:          themeIdx,
@@ -220,10 +336,10 @@ func initialModel(scanPath string, flagConfigPath string) model {
 		menuIndex:           0,
 		currentScreen:       screenMain,
 		testsMenuIndex:      0,
-		testsMenuItems:      []string{"Know It All", "Test Mode"},
+		testsMenuItems:      []string{"Know It All", "Rerun Failures", "Test Mode", "Execution Strategy", "Flag Profile", "Flaky Check", "Benchmarks", "Fuzz", "Profile", "Cross-Package Coverage"},
 		currentTestMode:     currentMode,
 		testModeIndex:       modeIndex,
-		testModeItems:       []string{"Unit", "Integration", "All"},
+		testModeItems:       modeItems,
 		themeMenuIndex:      0,
 		themeMenuItems:      []string{"Select Theme", "Edit Theme", "Reload Themes"},
 		themeSelectionMode:  themeSelectModeApply,
@@ -233,6 +349,20 @@ func initialModel(scanPath string, flagConfigPath string) model {
 		testResults:         make(map[string]*PackageTestResult),
 		testErrors:          make(map[string]error),
 		testsRunning:        make(map[string]bool),
+		benchmarksRunning:   make(map[string]bool),
+		fuzzRunning:         make(map[string]bool),
+		liveTests:           make(map[string]*testEventParser),
+		testCancels:         make(map[string]context.CancelFunc),
+		queuedRunOptions:    make(map[string]testRunOptions),
+		partialRuns:         make(map[string]bool),
+		batchRuns:           make(map[string]bool),
+		benchmarkResults:    make(map[string]*PackageBenchmarkResult),
+		benchmarkPrevious:   make(map[string]*PackageBenchmarkResult),
+		liveBenchmarks:      make(map[string]*benchmarkParser),
+		fuzzResults:         make(map[string]*FuzzResult),
+		liveFuzz:            make(map[string]*fuzzParser),
+		expandedTests:       make(map[string]map[string]bool),
+		runAllDurations:     make(map[executionStrategy]time.Duration),
 		scanError:           scanErr,
 		currentFocus:        focusLeftPanel,
 		rightPanelView:      viewSummary,
@@ -271,14 +401,531 @@ func (m *model) getTestModeForPath(dirPath string) testMode {
 	return testModeUnit // Default to unit if not configured
 }
 
-// runTestsCmd runs tests for a package and returns the result
-func runTestsCmd(packageDir string, packageName string, mode testMode) tea.Cmd {
+// getFlagProfileForPath returns the go test flag profile for a given directory path
+func (m *model) getFlagProfileForPath(dirPath string) FlagProfile {
+	absPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		absPath = dirPath
+	}
+	return m.config.FlagProfileByDir[absPath]
+}
+
+// startPackageTests clears previous results for a package, marks it running and
+// returns the command that runs its tests
+// Partial runs (opts selects some tests) keep the previous result so the new
+// outcomes can be merged into it
+func (m *model) startPackageTests(pkg TestPackage, opts testRunOptions) tea.Cmd {
+	if opts.isPartial() {
+		m.partialRuns[pkg.Name] = true
+	} else {
+		// Clear old results
+		delete(m.testResults, pkg.Name)
+	}
+	delete(m.testErrors, pkg.Name)
+	// Mark test as running
+	m.testsRunning[pkg.Name] = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.testCancels[pkg.Name] = cancel
+
+	// Use test mode and flag profile for this specific package's directory
+	pkgMode := m.getTestModeForPath(pkg.Path)
+	opts.profile = m.getFlagProfileForPath(pkg.Path)
+	opts.allTags = pkg.BuildTags()
+	opts.testFuncs = pkg.TestFuncs
+	opts.coverPkg = m.coverPkgPattern()
+	return runTestsCmd(ctx, cancel, pkg.Path, pkg.Name, pkgMode, opts)
+}
+
+// startPackageBenchmarks marks a package running and returns the command that
+// runs its benchmarks
+// Benchmarks occupy the package like a test run, so the two never overlap
+func (m *model) startPackageBenchmarks(pkg TestPackage) tea.Cmd {
+	delete(m.testErrors, pkg.Name)
+	m.benchmarksRunning[pkg.Name] = true
+	m.liveBenchmarks[pkg.Name] = newBenchmarkParser(&PackageBenchmarkResult{
+		PackagePath: pkg.Name,
+		Status:      "RUNNING",
+		Started:     time.Now(),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.testCancels[pkg.Name] = cancel
+
+	opts := testRunOptions{
+		profile: m.getFlagProfileForPath(pkg.Path),
+		allTags: pkg.BuildTags(),
+		count:   m.config.BenchmarkCount,
+	}
+	return runBenchmarksCmd(ctx, cancel, pkg.Path, pkg.Name, m.getTestModeForPath(pkg.Path), opts)
+}
+
+// startPackageFuzz marks a package running and returns the command that fuzzes
+// one of its targets for duration
+func (m *model) startPackageFuzz(pkg TestPackage, target string, duration time.Duration) tea.Cmd {
+	delete(m.testErrors, pkg.Name)
+	m.fuzzRunning[pkg.Name] = true
+	m.liveFuzz[pkg.Name] = newFuzzParser(&FuzzResult{
+		PackagePath: pkg.Name,
+		Target:      target,
+		Status:      "RUNNING",
+		FuzzTime:    duration,
+		Started:     time.Now(),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.testCancels[pkg.Name] = cancel
+
+	opts := testRunOptions{
+		profile: m.getFlagProfileForPath(pkg.Path),
+		allTags: pkg.BuildTags(),
+	}
+	return runFuzzCmd(ctx, cancel, pkg.Path, pkg.Name, target, duration, m.getTestModeForPath(pkg.Path), opts)
+}
+
+// storeTestResult records a finished run, merging partial reruns into the
+// package's previous result
+func (m *model) storeTestResult(result *PackageTestResult) {
+	if m.partialRuns[result.PackagePath] {
+		if existing, exists := m.testResults[result.PackagePath]; exists {
+			result = mergeTestResults(existing, result)
+		}
+	}
+	m.testResults[result.PackagePath] = result
+	attributeCoverage(m.testResults, result.PackagePath)
+}
+
+// packageStatuses returns the current status of every package that has one
+// Running and queued packages take precedence over stored results
+func (m *model) packageStatuses() map[string]string {
+	statuses := make(map[string]string)
+	for name, result := range m.testResults {
+		statuses[name] = result.Status
+	}
+	for name := range m.testErrors {
+		statuses[name] = "ERROR"
+	}
+	for _, pkg := range m.testQueue {
+		statuses[pkg.Name] = "QUEUED"
+	}
+	for name := range m.benchmarksRunning {
+		statuses[name] = "RUNNING"
+	}
+	for name := range m.fuzzRunning {
+		statuses[name] = "RUNNING"
+	}
+	for name := range m.testsRunning {
+		statuses[name] = "RUNNING"
+		if live, exists := m.liveTests[name]; exists && hungTestCount(live.result) > 0 {
+			statuses[name] = "HANG"
+		}
+	}
+	return statuses
+}
+
+// packageFailureKinds returns why each package whose stored result is shown as
+// its status failed; packages that passed or are running again are left out
+func (m *model) packageFailureKinds() map[string]FailureKind {
+	statuses := m.packageStatuses()
+	kinds := make(map[string]FailureKind)
+	for name, result := range m.testResults {
+		if result.FailureKind != FailureNone && statuses[name] == result.Status {
+			kinds[name] = result.FailureKind
+		}
+	}
+	return kinds
+}
+
+// parallelLimit returns how many packages may be tested at once
+func (m *model) parallelLimit() int {
+	if m.config.MaxParallelPackages > 0 {
+		return m.config.MaxParallelPackages
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// startNextQueuedTests starts packages from the "Run All" queue until the
+// parallel limit is reached. Ends the run once the queue is empty and
+// nothing is left in flight
+func (m *model) startNextQueuedTests() tea.Cmd {
+	if !m.runAllInProgress {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	var deferred []TestPackage
+	for len(m.testQueue) > 0 && len(m.testsRunning) < m.parallelLimit() {
+		pkg := m.testQueue[0]
+		m.testQueue = m.testQueue[1:]
+		// Skip packages that are already being tested
+		if m.testsRunning[pkg.Name] {
+			continue
+		}
+		// Packages busy benchmarking or fuzzing are tested once they finish
+		if m.benchmarkingOrFuzzing(pkg.Name) {
+			deferred = append(deferred, pkg)
+			continue
+		}
+		// Each run gets its own coverage temp file and output capture
+		cmds = append(cmds, m.startPackageTests(pkg, m.queuedRunOptions[pkg.Name]))
+		delete(m.queuedRunOptions, pkg.Name)
+	}
+	m.testQueue = append(m.testQueue, deferred...)
+
+	if len(m.testQueue) == 0 && len(m.testsRunning) == 0 {
+		// All tests complete
+		m.runAllInProgress = false
+		// Only full runs are timed, for comparing execution strategies
+		if m.runAllStrategy != "" {
+			m.runAllDurations[m.runAllStrategy] = time.Since(m.runAllStarted)
+			LogInfo("Know It All complete",
+				"strategy", m.runAllStrategy,
+				"duration", m.runAllDurations[m.runAllStrategy].String(),
+			)
+		}
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// executionStrategy returns the Know It All execution strategy for the scanned project
+func (m *model) executionStrategy() executionStrategy {
+	absPath, err := filepath.Abs(m.scanPath)
+	if err != nil {
+		absPath = m.scanPath
+	}
+	if strategy, exists := m.config.StrategyByDir[absPath]; exists && executionStrategy(strategy) == strategySingle {
+		return strategySingle
+	}
+	return strategyPerPackage
+}
+
+// coverPkgSetting returns the cross-package coverage setting of the scanned
+// project: "module", -coverpkg patterns, or "" when it is off
+func (m *model) coverPkgSetting() string {
+	absPath, err := filepath.Abs(m.scanPath)
+	if err != nil {
+		absPath = m.scanPath
+	}
+	return m.config.CoverPkgByDir[absPath]
+}
+
+// coverPkgPattern returns the -coverpkg patterns runs of the scanned project use,
+// or "" to measure only the package under test
+// "module" measures every package of the module; other settings are passed as-is
+func (m *model) coverPkgPattern() string {
+	setting := m.coverPkgSetting()
+	if setting != coverPkgModule {
+		return setting
+	}
+	if m.modulePath == "" {
+		LogWarn("Cross-package coverage needs a go.mod, measuring packages on their own", "scan_path", m.scanPath)
+		return ""
+	}
+	return m.modulePath + "/..."
+}
+
+// startRunAll starts testing every package using the project's execution strategy
+func (m *model) startRunAll() tea.Cmd {
+	m.runAllInProgress = true
+	m.runAllStarted = time.Now()
+	m.runAllStrategy = m.executionStrategy()
+	if m.runAllStrategy == strategySingle && m.coverPkgPattern() != "" {
+		// A single invocation merges the profiles of every package, losing which
+		// tests covered what
+		LogInfo("Cross-package coverage is on, running packages separately", "coverpkg", m.coverPkgPattern())
+		m.runAllStrategy = strategyPerPackage
+	}
+
+	if m.runAllStrategy == strategySingle {
+		// Packages busy benchmarking or fuzzing miss the batch and are tested on
+		// their own once they finish
+		m.testQueue = nil
+		m.queuedRunOptions = make(map[string]testRunOptions)
+		for _, pkg := range m.testPackages {
+			if m.benchmarkingOrFuzzing(pkg.Name) {
+				m.testQueue = append(m.testQueue, pkg)
+			}
+		}
+		return m.startBatchTests(m.testPackages)
+	}
+
+	// Initialize test queue with all packages
+	m.testQueue = make([]TestPackage, len(m.testPackages))
+	copy(m.testQueue, m.testPackages)
+	m.queuedRunOptions = make(map[string]testRunOptions)
+
+	// Clear old results and start the first batch of tests
+	return m.startNextQueuedTests()
+}
+
+// startRerunFailures reruns only the failed tests of every package, using an
+// anchored -run pattern per package, and merges the outcomes into the existing results
+// Returns nil if no package has failed tests, or while Know It All is running
+// since the rerun would replace its queue
+func (m *model) startRerunFailures() tea.Cmd {
+	if m.runAllInProgress {
+		LogInfo("Rerun Failures ignored while Know It All is running", "queued", len(m.testQueue))
+		return nil
+	}
+	m.testQueue = nil
+	m.queuedRunOptions = make(map[string]testRunOptions)
+	for _, pkg := range m.testPackages {
+		result, exists := m.testResults[pkg.Name]
+		if !exists || m.packageBusy(pkg.Name) {
+			continue
+		}
+		pattern := buildRunPattern(failedTestNames(result))
+		if pattern == "" {
+			continue
+		}
+		m.testQueue = append(m.testQueue, pkg)
+		m.queuedRunOptions[pkg.Name] = testRunOptions{runPattern: pattern}
+		LogInfo("Queued failed tests for rerun", "package", pkg.Name, "run", pattern)
+	}
+	if len(m.testQueue) == 0 {
+		return nil
+	}
+
+	m.runAllInProgress = true
+	m.runAllStarted = time.Now()
+	m.runAllStrategy = "" // Partial run - not timed
+	return m.startNextQueuedTests()
+}
+
+// startBatchTests tests packages with a single go test invocation per test mode
+// The packages share one cancel function, so cancelling any of them stops the batch
+func (m *model) startBatchTests(packages []TestPackage) tea.Cmd {
+	// Group by test mode and flag profile - each group is one go test invocation
+	byKey := make(map[string]*batchGroup)
+	var keys []string
+	for _, pkg := range packages {
+		// Skip packages that are already being tested
+		if m.packageBusy(pkg.Name) {
+			continue
+		}
+		pkgMode := m.getTestModeForPath(pkg.Path)
+		profile := m.getFlagProfileForPath(pkg.Path)
+		key := string(pkgMode) + "|" + profile.String()
+		group, exists := byKey[key]
+		if !exists {
+			group = &batchGroup{mode: pkgMode, profile: profile}
+			byKey[key] = group
+			keys = append(keys, key)
+		}
+		group.packages = append(group.packages, pkg)
+	}
+	if len(keys) == 0 {
+		return m.startNextQueuedTests()
+	}
+
+	sort.Strings(keys)
+	groups := make([]batchGroup, 0, len(keys))
+	for _, key := range keys {
+		groups = append(groups, *byKey[key])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, group := range groups {
+		for _, pkg := range group.packages {
+			// Clear old results and mark as running
+			delete(m.testResults, pkg.Name)
+			delete(m.testErrors, pkg.Name)
+			m.testsRunning[pkg.Name] = true
+			m.testCancels[pkg.Name] = cancel
+			m.batchRuns[pkg.Name] = true
+		}
+	}
+	return runBatchTestsCmd(ctx, cancel, m.scanPath, groups)
+}
+
+// finishPackageTests clears the running state of a package
+func (m *model) finishPackageTests(packageName string) {
+	delete(m.testsRunning, packageName)
+	delete(m.liveTests, packageName)
+	delete(m.partialRuns, packageName)
+	delete(m.batchRuns, packageName)
+	// The run's own command releases the context once it is done
+	delete(m.testCancels, packageName)
+}
+
+// finishPackageBenchmarks clears the benchmarking state of a package
+func (m *model) finishPackageBenchmarks(packageName string) {
+	delete(m.benchmarksRunning, packageName)
+	delete(m.liveBenchmarks, packageName)
+	delete(m.testCancels, packageName)
+}
+
+// finishPackageFuzz clears the fuzzing state of a package
+func (m *model) finishPackageFuzz(packageName string) {
+	delete(m.fuzzRunning, packageName)
+	delete(m.liveFuzz, packageName)
+	delete(m.testCancels, packageName)
+}
+
+// benchmarkingOrFuzzing reports whether a package is running benchmarks or being fuzzed
+func (m *model) benchmarkingOrFuzzing(packageName string) bool {
+	return m.benchmarksRunning[packageName] || m.fuzzRunning[packageName]
+}
+
+// packageBusy reports whether a package is being tested, benchmarked or fuzzed
+// Runs of a package never overlap, so nothing else may start while it is busy
+func (m *model) packageBusy(packageName string) bool {
+	return m.testsRunning[packageName] || m.benchmarkingOrFuzzing(packageName)
+}
+
+// cancelPackageTests cancels the run of a single package
+// Returns false if the package is not running
+func (m *model) cancelPackageTests(packageName string) bool {
+	cancel, exists := m.testCancels[packageName]
+	if !exists {
+		return false
+	}
+	LogInfo("Cancelling tests", "package", packageName)
+	cancel()
+	return true
+}
+
+// cancelAllTests drains the "Run All" queue and cancels every running package
+func (m *model) cancelAllTests() {
+	m.testQueue = nil
+	m.runAllInProgress = false
+	for packageName := range m.testCancels {
+		m.cancelPackageTests(packageName)
+	}
+}
+
+// uiExited is closed when the program exits, after which nothing reads the
+// streams of runs that are still finishing
+var uiExited = make(chan struct{})
+
+// sendStream sends msg on a run's stream, dropping it once the UI has exited
+// Otherwise a run with more events than the stream buffers would block forever
+// and keep main waiting for its go test process
+func sendStream(stream chan<- tea.Msg, msg tea.Msg) {
+	select {
+	case stream <- msg:
+	case <-uiExited:
+	}
+}
+
+// runTestsCmd runs tests for a package in the background
+// Progress events are streamed back as testEventMsgs, followed by a final
+// testCompleteMsg or testErrorMsg, after which the stream is closed
+// cancel is called once the run has finished
+func runTestsCmd(ctx context.Context, cancel context.CancelFunc, packageDir string, packageName string, mode testMode, opts testRunOptions) tea.Cmd {
+	return func() tea.Msg {
+		stream := make(chan tea.Msg, 64)
+		go func() {
+			defer close(stream)
+			defer cancel()
+			result, err := RunTests(ctx, packageDir, packageName, mode, opts, func(event TestEvent) {
+				sendStream(stream, testEventMsg{packageName: packageName, event: event, stream: stream})
+			})
+			if err != nil {
+				sendStream(stream, testErrorMsg{packageName: packageName, err: err})
+				return
+			}
+			sendStream(stream, testCompleteMsg{result: result})
+		}()
+		return <-stream
+	}
+}
+
+// runBenchmarksCmd runs the benchmarks of a package in the background
+// Events are streamed back as benchmarkEventMsgs, followed by a final
+// benchmarkCompleteMsg or testErrorMsg, after which the stream is closed
+func runBenchmarksCmd(ctx context.Context, cancel context.CancelFunc, packageDir string, packageName string, mode testMode, opts testRunOptions) tea.Cmd {
+	return func() tea.Msg {
+		stream := make(chan tea.Msg, 64)
+		go func() {
+			defer close(stream)
+			defer cancel()
+			result, err := RunBenchmarks(ctx, packageDir, packageName, mode, opts, func(event TestEvent) {
+				sendStream(stream, benchmarkEventMsg{packageName: packageName, event: event, stream: stream})
+			})
+			if err != nil {
+				sendStream(stream, testErrorMsg{packageName: packageName, err: err})
+				return
+			}
+			sendStream(stream, benchmarkCompleteMsg{result: result})
+		}()
+		return <-stream
+	}
+}
+
+// runFuzzCmd fuzzes a target in the background
+// Events are streamed back as fuzzEventMsgs, followed by a final
+// fuzzCompleteMsg or testErrorMsg, after which the stream is closed
+func runFuzzCmd(ctx context.Context, cancel context.CancelFunc, packageDir string, packageName string, target string, duration time.Duration, mode testMode, opts testRunOptions) tea.Cmd {
+	return func() tea.Msg {
+		stream := make(chan tea.Msg, 64)
+		go func() {
+			defer close(stream)
+			defer cancel()
+			result, err := RunFuzz(ctx, packageDir, packageName, target, duration, mode, opts, func(event TestEvent) {
+				sendStream(stream, fuzzEventMsg{packageName: packageName, event: event, stream: stream})
+			})
+			if err != nil {
+				sendStream(stream, testErrorMsg{packageName: packageName, err: err})
+				return
+			}
+			sendStream(stream, fuzzCompleteMsg{result: result})
+		}()
+		return <-stream
+	}
+}
+
+// runBatchTestsCmd runs groups of packages (one go test invocation per test mode
+// and flag profile) in the background, streaming events and per-package results on one stream
+// cancel is called once the whole batch has finished
+func runBatchTestsCmd(ctx context.Context, cancel context.CancelFunc, rootDir string, groups []batchGroup) tea.Cmd {
+	return func() tea.Msg {
+		stream := make(chan tea.Msg, 64)
+		go func() {
+			defer close(stream)
+			defer cancel()
+
+			for _, group := range groups {
+				packages := group.packages
+				if ctx.Err() != nil {
+					// Cancelled while an earlier group ran - the rest never start
+					for _, result := range cancelledBatchResults(packages) {
+						sendStream(stream, testCompleteMsg{result: result, stream: stream})
+					}
+					continue
+				}
+				opts := testRunOptions{profile: group.profile}
+				results, err := RunTestsBatch(ctx, rootDir, packages, group.mode, opts, func(packageName string, event TestEvent) {
+					sendStream(stream, testEventMsg{packageName: packageName, event: event, stream: stream})
+				})
+				for _, pkg := range packages {
+					if err != nil {
+						sendStream(stream, testErrorMsg{packageName: pkg.Name, err: err, stream: stream})
+					} else if result, exists := results[pkg.Name]; exists {
+						sendStream(stream, testCompleteMsg{result: result, stream: stream})
+					} else {
+						sendStream(stream, testErrorMsg{packageName: pkg.Name, err: fmt.Errorf("go test reported no results for %s", pkg.Name), stream: stream})
+					}
+				}
+			}
+		}()
+		return <-stream
+	}
+}
+
+// waitForTestStream returns a command that delivers the next message from a test stream
+// Returns nil once the stream has been closed
+func waitForTestStream(stream <-chan tea.Msg) tea.Cmd {
+	if stream == nil {
+		return nil
+	}
 	return func() tea.Msg {
-		result, err := RunTests(packageDir, packageName, mode)
-		if err != nil {
-			return testErrorMsg{packageName: packageName, err: err}
+		msg, ok := <-stream
+		if !ok {
+			return nil
 		}
-		return testCompleteMsg{result: result}
+		return msg
 	}
 }
 
@@ -290,59 +937,82 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ready = true
 		return &m, nil
 
+	case testEventMsg:
+		// Feed the event into the live result for this package
+		parser, exists := m.liveTests[msg.packageName]
+		if !exists {
+			parser = newTestEventParser(&PackageTestResult{
+				PackagePath: msg.packageName,
+				Status:      "RUNNING",
+			})
+			m.liveTests[msg.packageName] = parser
+		}
+		parser.Consume(msg.event)
+		// Only the test under the cursor shows its output while running
+		parser.exposeOutput(m.selectedTestName)
+		return &m, tea.Batch(waitForTestStream(msg.stream), m.startHangWatch())
+
+	case benchmarkEventMsg:
+		if parser, exists := m.liveBenchmarks[msg.packageName]; exists {
+			parser.Consume(msg.event)
+		}
+		// The hang watch ticks keep the elapsed time moving between events
+		return &m, tea.Batch(waitForTestStream(msg.stream), m.startHangWatch())
+
+	case benchmarkCompleteMsg:
+		// The run it replaces becomes the baseline to compare against
+		if existing, exists := m.benchmarkResults[msg.result.PackagePath]; exists && existing.Status != "CANCELLED" && len(existing.Benchmarks) > 0 {
+			m.benchmarkPrevious[msg.result.PackagePath] = existing
+		}
+		m.benchmarkResults[msg.result.PackagePath] = msg.result
+		m.finishPackageBenchmarks(msg.result.PackagePath)
+		return &m, m.startNextQueuedTests()
+
+	case fuzzEventMsg:
+		if parser, exists := m.liveFuzz[msg.packageName]; exists {
+			parser.Consume(msg.event)
+		}
+		return &m, tea.Batch(waitForTestStream(msg.stream), m.startHangWatch())
+
+	case fuzzCompleteMsg:
+		m.fuzzResults[fuzzKey(msg.result.PackagePath, msg.result.Target)] = msg.result
+		m.finishPackageFuzz(msg.result.PackagePath)
+		return &m, m.startNextQueuedTests()
+
+	case hangCheckMsg:
+		// Stop checking once nothing is running; the next test event restarts it
+		if len(m.testsRunning) == 0 && len(m.benchmarksRunning) == 0 && len(m.fuzzRunning) == 0 {
+			m.hangWatchActive = false
+			return &m, nil
+		}
+		m.checkForHungTests(time.Time(msg))
+		return &m, hangCheckCmd()
+
 	case testCompleteMsg:
 		// Store test result
 		if msg.result != nil {
-			m.testResults[msg.result.PackagePath] = msg.result
+			m.storeTestResult(msg.result)
 			// Clear running state
-			delete(m.testsRunning, msg.result.PackagePath)
+			m.finishPackageTests(msg.result.PackagePath)
 		}
 
 		// If "Run All" is in progress, start next test in queue
-		if m.runAllInProgress && len(m.testQueue) > 0 {
-			pkg := m.testQueue[0]
-			m.testQueue = m.testQueue[1:]
-			// Clear old results and errors
-			delete(m.testResults, pkg.Name)
-			delete(m.testErrors, pkg.Name)
-			// Mark test as running
-			m.testsRunning[pkg.Name] = true
-			// Use test mode for this specific package's directory
-			pkgMode := m.getTestModeForPath(pkg.Path)
-			return &m, runTestsCmd(pkg.Path, pkg.Name, pkgMode)
-		} else if m.runAllInProgress && len(m.testQueue) == 0 {
-			// All tests complete
-			m.runAllInProgress = false
-		}
-
-		return &m, nil
+		return &m, tea.Batch(waitForTestStream(msg.stream), m.startNextQueuedTests())
 
 	case testErrorMsg:
 		// Store test error
 		m.testErrors[msg.packageName] = msg.err
 		// Clear running state
-		delete(m.testsRunning, msg.packageName)
+		m.finishPackageTests(msg.packageName)
+		m.finishPackageBenchmarks(msg.packageName)
+		m.finishPackageFuzz(msg.packageName)
 
 		// If "Run All" is in progress, continue with next test even after error
-		if m.runAllInProgress && len(m.testQueue) > 0 {
-			pkg := m.testQueue[0]
-			m.testQueue = m.testQueue[1:]
-			// Clear old results and errors
-			delete(m.testResults, pkg.Name)
-			delete(m.testErrors, pkg.Name)
-			// Mark test as running
-			m.testsRunning[pkg.Name] = true
-			// Use test mode for this specific package's directory
-			pkgMode := m.getTestModeForPath(pkg.Path)
-			return &m, runTestsCmd(pkg.Path, pkg.Name, pkgMode)
-		} else if m.runAllInProgress && len(m.testQueue) == 0 {
-			// All tests complete (or stopped due to errors)
-			m.runAllInProgress = false
-		}
-
-		return &m, nil
+		return &m, tea.Batch(waitForTestStream(msg.stream), m.startNextQueuedTests())
 
 	case tea.KeyMsg:
+		m.statusNotice = ""
+
 		// Priority 1: Handle text input (highest priority to prevent navigation interference)
 		if handleTextInput(&m, msg) {
 			return &m, nil
@@ -358,7 +1028,42 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return &m, cmd
 		}
 
-		// Priority 4: Handle screen-specific keys
+		// Priority 4: Handle the test cursor and the views opened from test details
+		if handled, cmd := handleTestSelectionKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleGoroutineDumpKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleRaceReportKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handlePanicStackKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleBuildErrorKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleBenchmarkKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleBenchmarkCompareKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleFuzzKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleProfileKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleTimelineKeys(&m, msg); handled {
+			return &m, cmd
+		}
+		if handled, cmd := handleCoverageKeys(&m, msg); handled {
+			return &m, cmd
+		}
+
+		// Priority 5: Handle screen-specific keys
 		if handled, cmd := handleMainScreenKeys(&m, msg); handled {
 			return &m, cmd
 		}
@@ -383,6 +1088,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if handled, cmd := handleThemeEditorKeys(&m, msg); handled {
 			return &m, cmd
 		}
+		if handled, cmd := handleFlagProfileEditorKeys(&m, msg); handled {
+			return &m, cmd
+		}
 	}
 
 	return &m, nil
@@ -419,6 +1127,28 @@ func (m *model) View() string {
 		content = m.renderFullTestResults()
 	case screenFullCoverageGaps:
 		content = m.renderFullCoverageGaps()
+	case screenFlagProfileEditor:
+		content = m.renderFlagProfileEditor()
+	case screenGoroutineDump:
+		content = m.renderGoroutineDump()
+	case screenRaceReport:
+		content = m.renderRaceReport()
+	case screenPanicStack:
+		content = m.renderPanicStack()
+	case screenBuildErrors:
+		content = m.renderBuildErrors()
+	case screenBenchmarks:
+		content = m.renderBenchmarks()
+	case screenBenchmarkCompare:
+		content = m.renderBenchmarkCompare()
+	case screenFuzz:
+		content = m.renderFuzz()
+	case screenProfile:
+		content = m.renderProfile()
+	case screenTimeline:
+		content = m.renderTimeline()
+	case screenCoverage:
+		content = m.renderCoverage()
 	default:
 		content = m.renderMainScreen()
 	}
@@ -447,7 +1177,12 @@ func main() {
 
 	m := initialModel(scanPath, *configPath)
 	p := tea.NewProgram(&m, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	_, err := p.Run()
+	// Don't leave go test processes running after the UI exits
+	close(uiExited)
+	m.cancelAllTests()
+	runningTestProcesses.Wait()
+	if err != nil {
 		fmt.Printf("Error: %v", err)
 		os.Exit(1)
 	}
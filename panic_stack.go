@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PanicStackState holds the state for the panic stack view
+type PanicStackState struct {
+	packageName string
+	selected    int          // Index of the row under the cursor
+	expanded    map[int]bool // First frame of a runtime/testing group -> whether its frames are shown
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+// newPanicStackState opens the stack with the cursor on the first frame in the module
+func newPanicStackState(packageName string, report *PanicReport, modulePath string, returnTo appScreen) PanicStackState {
+	state := PanicStackState{
+		packageName: packageName,
+		expanded:    make(map[int]bool),
+		returnTo:    returnTo,
+	}
+	first := firstModuleFrame(report.Goroutine.Frames, modulePath)
+	for i, row := range panicStackRows(report.Goroutine.Frames, state.expanded) {
+		if row.frame == first {
+			state.selected = i
+		}
+	}
+	return state
+}
+
+// SelectedFrame returns the frame under the cursor
+// Returns false when the cursor is on a collapsed group
+func (s PanicStackState) SelectedFrame(report *PanicReport) (StackFrame, bool) {
+	rows := panicStackRows(report.Goroutine.Frames, s.expanded)
+	if s.selected < 0 || s.selected >= len(rows) || rows[s.selected].collapsed > 0 {
+		return StackFrame{}, false
+	}
+	return report.Goroutine.Frames[rows[s.selected].frame], true
+}
+
+// panicStackRow is one selectable row of the panic stack view
+type panicStackRow struct {
+	frame     int // Index of the frame shown, or of the first frame of a collapsed group
+	group     int // First frame of the runtime/testing group the row belongs to, -1 for other frames
+	collapsed int // Frames folded into this row, 0 for a row showing a single frame
+}
+
+// panicStackRows lays out the frames of a stack as rows: consecutive runtime and
+// testing frames fold into a single row unless their group is expanded
+func panicStackRows(frames []StackFrame, expanded map[int]bool) []panicStackRow {
+	var rows []panicStackRow
+	for i := 0; i < len(frames); {
+		if !isRuntimeFrame(frames[i]) {
+			rows = append(rows, panicStackRow{frame: i, group: -1})
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(frames) && isRuntimeFrame(frames[end]) {
+			end++
+		}
+		if expanded[i] {
+			for j := i; j < end; j++ {
+				rows = append(rows, panicStackRow{frame: j, group: i})
+			}
+		} else {
+			rows = append(rows, panicStackRow{frame: i, group: i, collapsed: end - i})
+		}
+		i = end
+	}
+	return rows
+}
+
+// FormatPanicStack renders a panic and the stack of the goroutine that panicked
+// Runtime and testing frames are folded unless expanded, and the first frame in
+// the module is highlighted
+// Returns the content and the line of the selected row
+func FormatPanicStack(report *PanicReport, modulePath string, theme Theme, state PanicStackState) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	moduleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(failStyle.Render("panic: "+report.Message) + "\n")
+	if report.Test != "" {
+		output.WriteString(normalStyle.Render("in "+report.Test) + "\n")
+	}
+	goroutine := report.Goroutine
+	output.WriteString(helpStyle.Render(fmt.Sprintf("goroutine %d [%s]", goroutine.ID, goroutine.State)) + "\n\n")
+
+	frames := goroutine.Frames
+	first := firstModuleFrame(frames, modulePath)
+	cursorLine := 0
+	for i, row := range panicStackRows(frames, state.expanded) {
+		prefix := "  "
+		if i == state.selected {
+			prefix = testCursorMarker
+			cursorLine = strings.Count(output.String(), "\n")
+		}
+
+		if row.collapsed > 0 {
+			line := fmt.Sprintf("%s▸ %d runtime/testing frame(s)", prefix, row.collapsed)
+			if i == state.selected {
+				output.WriteString(selectedStyle.Render(line) + "\n")
+			} else {
+				output.WriteString(helpStyle.Render(line) + "\n")
+			}
+			continue
+		}
+
+		frame := frames[row.frame]
+		call := prefix + frame.Function + "()"
+		if row.group >= 0 {
+			call = prefix + "▾ " + frame.Function + "()"
+		}
+		switch {
+		case i == state.selected:
+			output.WriteString(selectedStyle.Render(call))
+		case row.frame == first:
+			output.WriteString(moduleStyle.Render(call))
+		case isModuleFrame(frame, modulePath):
+			output.WriteString(metricStyle.Render(call))
+		default:
+			output.WriteString(normalStyle.Render(call))
+		}
+		if row.frame == first {
+			output.WriteString(moduleStyle.Render("  ← first frame in module"))
+		}
+		output.WriteString("\n" + helpStyle.Render("        "+frame.Location()) + "\n")
+	}
+
+	if goroutine.CreatedBy != nil {
+		output.WriteString("\n" + normalStyle.Render("created by "+goroutine.CreatedBy.Function) + "\n")
+		output.WriteString(helpStyle.Render("        "+goroutine.CreatedBy.Location()) + "\n")
+	}
+
+	return output.String(), cursorLine
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A minimal reader for the pprof profile format (profile.proto, usually gzipped)
+// Only what the top-functions view needs is decoded: sample types, samples,
+// locations with their lines, functions and the string table
+
+// ProfileValueType describes one value of every sample, e.g. "cpu" in "nanoseconds"
+type ProfileValueType struct {
+	Type string
+	Unit string
+}
+
+// ProfileSample is one stack with its values, one per sample type
+type ProfileSample struct {
+	LocationIDs []uint64 // Leaf first
+	Values      []int64
+}
+
+// ProfileLine is a source line of a location; inlined calls give a location several
+type ProfileLine struct {
+	FunctionID uint64
+	Line       int64
+}
+
+// ProfileLocation is a program counter resolved to source lines, innermost first
+type ProfileLocation struct {
+	ID    uint64
+	Lines []ProfileLine
+}
+
+// ProfileFunction is a function referenced by locations
+type ProfileFunction struct {
+	ID       uint64
+	Name     string
+	Filename string
+}
+
+// Profile is a decoded pprof profile
+type Profile struct {
+	SampleTypes       []ProfileValueType
+	Samples           []ProfileSample
+	Locations         map[uint64]ProfileLocation
+	Functions         map[uint64]ProfileFunction
+	DefaultSampleType string
+	DurationNanos     int64
+}
+
+// Field numbers of profile.proto
+const (
+	profileFieldSampleType        = 1
+	profileFieldSample            = 2
+	profileFieldLocation          = 4
+	profileFieldFunction          = 5
+	profileFieldStringTable       = 6
+	profileFieldDurationNanos     = 10
+	profileFieldDefaultSampleType = 14
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// errTruncatedProfile is returned when a field runs past the end of its message
+var errTruncatedProfile = errors.New("truncated profile")
+
+// ReadProfile reads and decodes a pprof profile file
+func ReadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProfile(data)
+}
+
+// ParseProfile decodes a pprof profile, gzipped or not
+func ParseProfile(data []byte) (*Profile, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("decompressing profile: %w", err)
+		}
+		if data, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("decompressing profile: %w", err)
+		}
+	}
+
+	// Strings are referenced by index and the table may come after its users,
+	// so indexes are kept until the whole message is read
+	type rawValueType struct{ typ, unit int64 }
+	type rawFunction struct {
+		id             uint64
+		name, filename int64
+	}
+	var (
+		stringTable       []string
+		sampleTypes       []rawValueType
+		functions         []rawFunction
+		defaultSampleType int64
+	)
+	profile := &Profile{
+		Locations: make(map[uint64]ProfileLocation),
+		Functions: make(map[uint64]ProfileFunction),
+	}
+
+	err := forEachField(data, func(field int, wire int, varint uint64, payload []byte) error {
+		switch field {
+		case profileFieldSampleType:
+			var vt rawValueType
+			err := forEachField(payload, func(field int, wire int, varint uint64, _ []byte) error {
+				switch field {
+				case 1:
+					vt.typ = int64(varint)
+				case 2:
+					vt.unit = int64(varint)
+				}
+				return nil
+			})
+			sampleTypes = append(sampleTypes, vt)
+			return err
+		case profileFieldSample:
+			sample, err := parseProfileSample(payload)
+			profile.Samples = append(profile.Samples, sample)
+			return err
+		case profileFieldLocation:
+			location, err := parseProfileLocation(payload)
+			profile.Locations[location.ID] = location
+			return err
+		case profileFieldFunction:
+			var fn rawFunction
+			err := forEachField(payload, func(field int, wire int, varint uint64, _ []byte) error {
+				switch field {
+				case 1:
+					fn.id = varint
+				case 2:
+					fn.name = int64(varint)
+				case 4:
+					fn.filename = int64(varint)
+				}
+				return nil
+			})
+			functions = append(functions, fn)
+			return err
+		case profileFieldStringTable:
+			stringTable = append(stringTable, string(payload))
+		case profileFieldDurationNanos:
+			profile.DurationNanos = int64(varint)
+		case profileFieldDefaultSampleType:
+			defaultSampleType = int64(varint)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := func(index int64) string {
+		if index < 0 || index >= int64(len(stringTable)) {
+			return ""
+		}
+		return stringTable[index]
+	}
+	for _, vt := range sampleTypes {
+		profile.SampleTypes = append(profile.SampleTypes, ProfileValueType{Type: lookup(vt.typ), Unit: lookup(vt.unit)})
+	}
+	for _, fn := range functions {
+		profile.Functions[fn.id] = ProfileFunction{ID: fn.id, Name: lookup(fn.name), Filename: lookup(fn.filename)}
+	}
+	profile.DefaultSampleType = lookup(defaultSampleType)
+	return profile, nil
+}
+
+// parseProfileSample decodes a Sample message
+// Repeated numbers may be packed or not, so both encodings are accepted
+func parseProfileSample(data []byte) (ProfileSample, error) {
+	var sample ProfileSample
+	err := forEachField(data, func(field int, wire int, varint uint64, payload []byte) error {
+		switch field {
+		case 1:
+			ids, err := repeatedVarints(wire, varint, payload)
+			sample.LocationIDs = append(sample.LocationIDs, ids...)
+			return err
+		case 2:
+			values, err := repeatedVarints(wire, varint, payload)
+			for _, v := range values {
+				sample.Values = append(sample.Values, int64(v))
+			}
+			return err
+		}
+		return nil
+	})
+	return sample, err
+}
+
+// parseProfileLocation decodes a Location message
+func parseProfileLocation(data []byte) (ProfileLocation, error) {
+	var location ProfileLocation
+	err := forEachField(data, func(field int, wire int, varint uint64, payload []byte) error {
+		switch field {
+		case 1:
+			location.ID = varint
+		case 4:
+			var line ProfileLine
+			err := forEachField(payload, func(field int, wire int, varint uint64, _ []byte) error {
+				switch field {
+				case 1:
+					line.FunctionID = varint
+				case 2:
+					line.Line = int64(varint)
+				}
+				return nil
+			})
+			location.Lines = append(location.Lines, line)
+			return err
+		}
+		return nil
+	})
+	return location, err
+}
+
+// repeatedVarints returns the numbers of a repeated varint field occurrence:
+// one number when unpacked, every number of the payload when packed
+func repeatedVarints(wire int, varint uint64, payload []byte) ([]uint64, error) {
+	if wire == wireVarint {
+		return []uint64{varint}, nil
+	}
+	var values []uint64
+	for len(payload) > 0 {
+		v, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return values, errTruncatedProfile
+		}
+		values = append(values, v)
+		payload = payload[n:]
+	}
+	return values, nil
+}
+
+// forEachField calls fn for every field of a protobuf message
+// varint holds the value of varint fields, payload the bytes of length-delimited ones;
+// fixed-width fields are skipped
+func forEachField(data []byte, fn func(field int, wire int, varint uint64, payload []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncatedProfile
+		}
+		data = data[n:]
+		field, wire := int(key>>3), int(key&7)
+
+		var varint uint64
+		var payload []byte
+		switch wire {
+		case wireVarint:
+			if varint, n = binary.Uvarint(data); n <= 0 {
+				return errTruncatedProfile
+			}
+			data = data[n:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errTruncatedProfile
+			}
+			payload = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case wireFixed64:
+			if len(data) < 8 {
+				return errTruncatedProfile
+			}
+			data = data[8:]
+			continue
+		case wireFixed32:
+			if len(data) < 4 {
+				return errTruncatedProfile
+			}
+			data = data[4:]
+			continue
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", wire)
+		}
+
+		if err := fn(field, wire, varint, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+// protoVarint appends a varint field to a protobuf message
+func protoVarint(message []byte, field int, v uint64) []byte {
+	message = binary.AppendUvarint(message, uint64(field)<<3|wireVarint)
+	return binary.AppendUvarint(message, v)
+}
+
+// protoBytes appends a length-delimited field to a protobuf message
+func protoBytes(message []byte, field int, payload []byte) []byte {
+	message = binary.AppendUvarint(message, uint64(field)<<3|wireBytes)
+	message = binary.AppendUvarint(message, uint64(len(payload)))
+	return append(message, payload...)
+}
+
+// protoPacked encodes numbers as the payload of a packed repeated field
+func protoPacked(values ...uint64) []byte {
+	var payload []byte
+	for _, v := range values {
+		payload = binary.AppendUvarint(payload, v)
+	}
+	return payload
+}
+
+// protoField is a field forEachField reported
+type protoField struct {
+	field, wire int
+	varint      uint64
+	payload     string
+}
+
+func TestForEachField(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []protoField
+		wantErr bool
+	}{
+		{name: "empty message"},
+		{
+			name: "single-byte varint",
+			data: protoVarint(nil, 1, 5),
+			want: []protoField{{field: 1, wire: wireVarint, varint: 5}},
+		},
+		{
+			name: "multi-byte varint",
+			data: []byte{0x08, 0xac, 0x02}, // Field 1 = 300
+			want: []protoField{{field: 1, wire: wireVarint, varint: 300}},
+		},
+		{
+			name: "largest varint",
+			data: protoVarint(nil, 2, ^uint64(0)),
+			want: []protoField{{field: 2, wire: wireVarint, varint: ^uint64(0)}},
+		},
+		{
+			name: "length-delimited",
+			data: []byte{0x32, 0x03, 'c', 'p', 'u'}, // Field 6 = "cpu"
+			want: []protoField{{field: 6, wire: wireBytes, payload: "cpu"}},
+		},
+		{
+			name: "empty length-delimited",
+			data: protoBytes(nil, 6, nil),
+			want: []protoField{{field: 6, wire: wireBytes}},
+		},
+		{
+			name: "large field number",
+			data: protoVarint(nil, 14, 3),
+			want: []protoField{{field: 14, wire: wireVarint, varint: 3}},
+		},
+		{
+			name: "fixed-width fields are skipped",
+			data: append([]byte{0x09, 1, 2, 3, 4, 5, 6, 7, 8, 0x15, 1, 2, 3, 4}, protoVarint(nil, 3, 7)...),
+			want: []protoField{{field: 3, wire: wireVarint, varint: 7}},
+		},
+		{
+			name:    "truncated key",
+			data:    []byte{0x80},
+			wantErr: true,
+		},
+		{
+			name:    "truncated varint",
+			data:    []byte{0x08, 0xac},
+			wantErr: true,
+		},
+		{
+			name:    "length past the end",
+			data:    []byte{0x32, 0x05, 'c', 'p', 'u'},
+			wantErr: true,
+		},
+		{
+			name:    "truncated fixed64",
+			data:    []byte{0x09, 1, 2, 3},
+			wantErr: true,
+		},
+		{
+			name:    "truncated fixed32",
+			data:    []byte{0x15, 1},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported wire type",
+			data:    []byte{0x0b}, // Field 1, start group
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []protoField
+			err := forEachField(tt.data, func(field int, wire int, varint uint64, payload []byte) error {
+				got = append(got, protoField{field, wire, varint, string(payload)})
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("forEachField() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got fields %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("field %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestForEachFieldStopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	data := protoVarint(protoVarint(nil, 1, 1), 2, 2)
+	err := forEachField(data, func(int, int, uint64, []byte) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("forEachField() = %v after %d calls, want %v after 1", err, calls, stop)
+	}
+}
+
+func TestRepeatedVarints(t *testing.T) {
+	tests := []struct {
+		name    string
+		wire    int
+		varint  uint64
+		payload []byte
+		want    []uint64
+		wantErr bool
+	}{
+		{name: "unpacked", wire: wireVarint, varint: 42, want: []uint64{42}},
+		{name: "packed", wire: wireBytes, payload: protoPacked(1, 300, 70000), want: []uint64{1, 300, 70000}},
+		{name: "packed empty", wire: wireBytes},
+		{name: "packed truncated", wire: wireBytes, payload: []byte{0x01, 0xac}, want: []uint64{1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repeatedVarints(tt.wire, tt.varint, tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("repeatedVarints() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("repeatedVarints() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("value %d = %d, want %d", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// testProfile encodes a CPU profile with two functions, main.work calling
+// main.hash, and samples in both encodings of repeated fields
+func testProfile() []byte {
+	strs := []string{"", "samples", "count", "cpu", "nanoseconds", "main.work", "main.hash", "/src/main.go"}
+
+	var message []byte
+	message = protoBytes(message, profileFieldSampleType, protoVarint(protoVarint(nil, 1, 1), 2, 2))
+	message = protoBytes(message, profileFieldSampleType, protoVarint(protoVarint(nil, 1, 3), 2, 4))
+
+	// Packed: 3 samples, 30ms in main.hash called from main.work
+	sample := protoBytes(nil, 1, protoPacked(2, 1))
+	sample = protoBytes(sample, 2, protoPacked(3, 30_000_000))
+	message = protoBytes(message, profileFieldSample, sample)
+	// Unpacked: 1 sample, 10ms in main.work itself
+	sample = protoVarint(nil, 1, 1)
+	sample = protoVarint(sample, 2, 1)
+	sample = protoVarint(sample, 2, 10_000_000)
+	message = protoBytes(message, profileFieldSample, sample)
+
+	location := protoVarint(nil, 1, 1)
+	location = protoBytes(location, 4, protoVarint(protoVarint(nil, 1, 10), 2, 12))
+	message = protoBytes(message, profileFieldLocation, location)
+	location = protoVarint(nil, 1, 2)
+	location = protoBytes(location, 4, protoVarint(protoVarint(nil, 1, 20), 2, 30))
+	message = protoBytes(message, profileFieldLocation, location)
+
+	message = protoBytes(message, profileFieldFunction, protoVarint(protoVarint(protoVarint(nil, 1, 10), 2, 5), 4, 7))
+	message = protoBytes(message, profileFieldFunction, protoVarint(protoVarint(protoVarint(nil, 1, 20), 2, 6), 4, 7))
+
+	// The string table comes after the fields referencing it
+	for _, s := range strs {
+		message = protoBytes(message, profileFieldStringTable, []byte(s))
+	}
+	message = protoVarint(message, profileFieldDurationNanos, 1_000_000_000)
+	message = protoVarint(message, profileFieldDefaultSampleType, 3)
+	return message
+}
+
+// gzipped compresses data as profiles are written to disk
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "plain", data: testProfile()},
+		{name: "gzipped", data: gzipped(t, testProfile())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := ParseProfile(tt.data)
+			if err != nil {
+				t.Fatalf("ParseProfile() error = %v", err)
+			}
+
+			if len(profile.SampleTypes) != 2 || profile.SampleTypes[1] != (ProfileValueType{"cpu", "nanoseconds"}) {
+				t.Errorf("SampleTypes = %+v, want samples/count and cpu/nanoseconds", profile.SampleTypes)
+			}
+			if profile.DefaultSampleType != "cpu" || profile.DurationNanos != 1_000_000_000 {
+				t.Errorf("DefaultSampleType = %q, DurationNanos = %d, want cpu, 1s", profile.DefaultSampleType, profile.DurationNanos)
+			}
+			if len(profile.Samples) != 2 {
+				t.Fatalf("got %d samples, want 2", len(profile.Samples))
+			}
+			if ids := profile.Samples[0].LocationIDs; len(ids) != 2 || ids[0] != 2 || ids[1] != 1 {
+				t.Errorf("packed LocationIDs = %v, want [2 1]", ids)
+			}
+			if values := profile.Samples[1].Values; len(values) != 2 || values[1] != 10_000_000 {
+				t.Errorf("unpacked Values = %v, want [1 10000000]", values)
+			}
+			if fn := profile.Functions[20]; fn.Name != "main.hash" || fn.Filename != "/src/main.go" {
+				t.Errorf("function 20 = %+v, want main.hash in /src/main.go", fn)
+			}
+			if lines := profile.Locations[2].Lines; len(lines) != 1 || lines[0] != (ProfileLine{FunctionID: 20, Line: 30}) {
+				t.Errorf("location 2 lines = %+v, want main.hash:30", lines)
+			}
+
+			index := profile.defaultSampleIndex()
+			if index != 1 {
+				t.Fatalf("defaultSampleIndex() = %d, want 1", index)
+			}
+			top, total := profile.TopFunctions(index, false)
+			if total != 40_000_000 || len(top) != 2 {
+				t.Fatalf("TopFunctions() = %+v, %d, want 2 functions, 40ms", top, total)
+			}
+			if top[0] != (profileFunctionCost{"main.hash", "/src/main.go", 30_000_000, 30_000_000}) ||
+				top[1] != (profileFunctionCost{"main.work", "/src/main.go", 10_000_000, 40_000_000}) {
+				t.Errorf("TopFunctions() = %+v, want main.hash 30ms flat, main.work 10ms flat 40ms cum", top)
+			}
+		})
+	}
+}
+
+func TestParseProfileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated message", data: testProfile()[:20]},
+		{name: "truncated sample", data: protoBytes(nil, profileFieldSample, []byte{0x08})},
+		{name: "bad gzip", data: []byte{0x1f, 0x8b, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseProfile(tt.data); err == nil {
+				t.Error("ParseProfile() error = nil, want an error")
+			}
+		})
+	}
+}
+
+// TestParseRuntimeProfile decodes a heap profile written by the runtime, as a
+// fixture of what go test -memprofile produces
+func TestParseRuntimeProfile(t *testing.T) {
+	defer func(rate int) { runtime.MemProfileRate = rate }(runtime.MemProfileRate)
+	runtime.MemProfileRate = 1
+
+	var keep [][]byte
+	for i := 0; i < 100; i++ {
+		keep = append(keep, make([]byte, 4096))
+	}
+	runtime.GC() // The profile holds allocations up to the last collection
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("allocs").WriteTo(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	runtime.KeepAlive(keep)
+
+	profile, err := ParseProfile(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseProfile() error = %v", err)
+	}
+
+	var types []string
+	for _, sampleType := range profile.SampleTypes {
+		types = append(types, sampleType.Type+"/"+sampleType.Unit)
+	}
+	if got, want := strings.Join(types, ","), "alloc_objects/count,alloc_space/bytes,inuse_objects/count,inuse_space/bytes"; got != want {
+		t.Errorf("SampleTypes = %s, want %s", got, want)
+	}
+	if len(profile.Samples) == 0 {
+		t.Fatal("profile has no samples")
+	}
+	for _, sample := range profile.Samples {
+		if len(sample.Values) != len(profile.SampleTypes) {
+			t.Fatalf("sample has %d values, want %d", len(sample.Values), len(profile.SampleTypes))
+		}
+		for _, id := range sample.LocationIDs {
+			location, exists := profile.Locations[id]
+			if !exists {
+				t.Fatalf("sample references unknown location %d", id)
+			}
+			for _, line := range location.Lines {
+				if profile.Functions[line.FunctionID].Name == "" {
+					t.Fatalf("location %d references unknown function %d", id, line.FunctionID)
+				}
+			}
+		}
+	}
+
+	top, total := profile.TopFunctions(profile.defaultSampleIndex(), true)
+	if total < 100*4096 {
+		t.Errorf("alloc_space total = %d, want at least %d", total, 100*4096)
+	}
+	found := false
+	for _, fn := range top {
+		if strings.HasSuffix(fn.Name, ".TestParseRuntimeProfile") {
+			found = fn.Cum >= 100*4096
+		}
+	}
+	if !found {
+		t.Error("TopFunctions() doesn't attribute the test's allocations to it")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package main
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// configureProcessGroup makes context cancellation kill go test and its whole
+// process tree, so test binaries spawned by go test die too
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		// taskkill /T terminates the process together with all of its children
+		return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+	}
+}
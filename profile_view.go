@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// profileTopN is how many of the most expensive functions the profile view lists
+const profileTopN = 50
+
+// ProfileViewState holds the state for the profile view
+type ProfileViewState struct {
+	packageName string
+	cpu, mem    *Profile // Parsed when the view opens; nil if missing or unreadable
+	cpuErr      error
+	memErr      error
+	memory      bool // Showing the memory profile rather than the CPU profile
+	sampleIndex int  // Sample type of the shown profile
+	byCum       bool // Sorted by cumulative rather than flat cost
+	selected    int
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+// newProfileViewState parses the profiles of a result for the profile view
+// The CPU profile is shown first unless the run only wrote a memory profile
+func newProfileViewState(result *PackageTestResult, returnTo appScreen) ProfileViewState {
+	state := ProfileViewState{
+		packageName: result.PackagePath,
+		returnTo:    returnTo,
+	}
+	if result.CPUProfile != "" {
+		state.cpu, state.cpuErr = ReadProfile(result.CPUProfile)
+	}
+	if result.MemProfile != "" {
+		state.mem, state.memErr = ReadProfile(result.MemProfile)
+	}
+	if state.cpuErr != nil {
+		LogWarn("Failed to parse CPU profile", "path", result.CPUProfile, "error", state.cpuErr)
+	}
+	if state.memErr != nil {
+		LogWarn("Failed to parse memory profile", "path", result.MemProfile, "error", state.memErr)
+	}
+	state.memory = result.CPUProfile == "" && result.MemProfile != ""
+	state.sampleIndex = state.defaultSampleIndex()
+	return state
+}
+
+// shown returns the profile on screen, its path and the error parsing it
+func (s ProfileViewState) shown(result *PackageTestResult) (*Profile, string, error) {
+	if s.memory {
+		return s.mem, result.MemProfile, s.memErr
+	}
+	return s.cpu, result.CPUProfile, s.cpuErr
+}
+
+// defaultSampleIndex returns the sample type to start the shown profile with
+func (s ProfileViewState) defaultSampleIndex() int {
+	profile := s.cpu
+	if s.memory {
+		profile = s.mem
+	}
+	if profile == nil {
+		return 0
+	}
+	return profile.defaultSampleIndex()
+}
+
+// FormatProfile renders the top functions of a package's CPU or memory profile
+// like go tool pprof's top: flat and cumulative cost with their shares
+// Returns the content and the line of the selected row
+func FormatProfile(result *PackageTestResult, theme Theme, state ProfileViewState, width int) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	headerStyle := lipgloss.NewStyle().Foreground(theme.NormalFg).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(normalStyle.Render("Profile: "+result.PackagePath) + "  ")
+	for _, tab := range []struct {
+		label  string
+		memory bool
+	}{{"CPU", false}, {"Memory", true}} {
+		if tab.memory == state.memory {
+			output.WriteString(selectedStyle.Render(" "+tab.label+" ") + " ")
+		} else {
+			output.WriteString(helpStyle.Render(" "+tab.label+" ") + " ")
+		}
+	}
+	output.WriteString(helpStyle.Render(" (c/m to switch)") + "\n")
+
+	profile, path, err := state.shown(result)
+	switch {
+	case err != nil:
+		output.WriteString("\n" + failStyle.Render("Failed to read "+path+": "+err.Error()) + "\n")
+		return output.String(), 0
+	case profile == nil:
+		output.WriteString("\n" + normalStyle.Render("The profiling run wrote no such profile.") + "\n")
+		return output.String(), 0
+	case len(profile.SampleTypes) == 0:
+		output.WriteString("\n" + normalStyle.Render("The profile has no sample types.") + "\n")
+		return output.String(), 0
+	}
+
+	sampleIndex := Clamp(state.sampleIndex, 0, len(profile.SampleTypes)-1)
+	sampleType := profile.SampleTypes[sampleIndex]
+	output.WriteString(normalStyle.Render("Sample type: "))
+	for i, st := range profile.SampleTypes {
+		if i == sampleIndex {
+			output.WriteString(selectedStyle.Render(" "+st.Type+" ") + " ")
+		} else {
+			output.WriteString(helpStyle.Render(" "+st.Type+" ") + " ")
+		}
+	}
+	output.WriteString(helpStyle.Render(" (←→ to switch)") + "\n")
+
+	functions, total := profile.TopFunctions(sampleIndex, state.byCum)
+	summary := "Total: " + formatProfileValue(total, sampleType.Unit)
+	if profile.DurationNanos > 0 {
+		summary += " over " + formatDuration(time.Duration(profile.DurationNanos))
+	}
+	output.WriteString(normalStyle.Render(summary) + helpStyle.Render(fmt.Sprintf("  (%d functions)", len(functions))) + "\n\n")
+	if total == 0 {
+		output.WriteString(normalStyle.Render("No samples of this type were recorded; the tests may have run too briefly.") + "\n")
+		return output.String(), 0
+	}
+
+	flatHeader, cumHeader := "flat", "cum"
+	if state.byCum {
+		cumHeader += " ▼"
+	} else {
+		flatHeader += " ▼"
+	}
+	output.WriteString(headerStyle.Render(fmt.Sprintf("  %10s %7s %7s %10s %7s  %s", flatHeader, "flat%", "sum%", cumHeader, "cum%", "function")) + "\n")
+	output.WriteString(separatorStyle.Render(strings.Repeat("─", Max(width, 20))) + "\n")
+
+	cursorLine := 0
+	var sum int64
+	for i, fn := range functions {
+		if i >= profileTopN {
+			output.WriteString(helpStyle.Render(fmt.Sprintf("  … %d more functions", len(functions)-profileTopN)) + "\n")
+			break
+		}
+		sum += fn.Flat
+		row := fmt.Sprintf("%10s %6.2f%% %6.2f%% %10s %6.2f%%  ",
+			formatProfileValue(fn.Flat, sampleType.Unit), percentOf(fn.Flat, total), percentOf(sum, total),
+			formatProfileValue(fn.Cum, sampleType.Unit), percentOf(fn.Cum, total))
+		if i == state.selected {
+			cursorLine = strings.Count(output.String(), "\n")
+			output.WriteString(selectedStyle.Render(testCursorMarker+row+fn.Name) + "\n")
+			if fn.Filename != "" {
+				output.WriteString(helpStyle.Render(strings.Repeat(" ", len(row)+2)+fn.Filename) + "\n")
+			}
+		} else {
+			output.WriteString(normalStyle.Render("  "+row) + metricStyle.Render(fn.Name) + "\n")
+		}
+	}
+
+	if result.ProfileBinary != "" {
+		output.WriteString("\n" + helpStyle.Render("go tool pprof "+result.ProfileBinary+" "+path) + "\n")
+	}
+	return output.String(), cursorLine
+}
+
+// formatProfileValue formats a profile value in the scale of its unit
+func formatProfileValue(value int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		if value == 0 {
+			return "0"
+		}
+		return formatBenchmarkValue(float64(value), unitNsPerOp)
+	case "bytes":
+		return formatBenchmarkValue(float64(value), unitBytesPerOp)
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+// percentOf returns value as a percentage of total
+func percentOf(value, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total) * 100
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Files a profiling run writes to its profile directory
+const (
+	cpuProfileFile    = "cpu.pprof"
+	memProfileFile    = "mem.pprof"
+	profileBinaryFile = "pkg.test"
+)
+
+// profileDir returns the directory the profiles of a package's last profiling run are kept in
+func profileDir(packageDir string) string {
+	return filepath.Join(os.TempDir(), "gapistotle", "profiles", dirKey(packageDir))
+}
+
+// prepareProfileDir empties the profile directory so no profile of an earlier run
+// is mistaken for this run's, and returns the go test flags that write into it
+// The test binary is kept there too (-o), as go tool pprof wants it for symbols
+func prepareProfileDir(dir string) ([]string, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return []string{
+		"-cpuprofile=" + filepath.Join(dir, cpuProfileFile),
+		"-memprofile=" + filepath.Join(dir, memProfileFile),
+		"-o=" + filepath.Join(dir, profileBinaryFile),
+	}, nil
+}
+
+// applyProfiles records the profiles a profiling run wrote
+// A test binary that failed to build or crashed may have written none
+func applyProfiles(result *PackageTestResult, dir string) {
+	exists := func(name string) string {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			return path
+		}
+		return ""
+	}
+	result.CPUProfile = exists(cpuProfileFile)
+	result.MemProfile = exists(memProfileFile)
+	result.ProfileBinary = exists(profileBinaryFile)
+	if result.CPUProfile == "" && result.MemProfile == "" {
+		LogWarn("Profiling run wrote no profiles", "package", result.PackagePath, "directory", dir)
+	}
+}
+
+// profileFunctionCost is the cost of one function for one sample type
+type profileFunctionCost struct {
+	Name     string
+	Filename string
+	Flat     int64 // Samples whose innermost frame is the function
+	Cum      int64 // Samples with the function anywhere on the stack
+}
+
+// defaultSampleIndex returns the sample type the view starts with: the profile's
+// default, else allocated bytes for memory profiles (what tests are usually
+// slow because of), else the last type as go tool pprof does
+func (p *Profile) defaultSampleIndex() int {
+	for _, preferred := range []string{p.DefaultSampleType, "alloc_space"} {
+		for i, sampleType := range p.SampleTypes {
+			if preferred != "" && sampleType.Type == preferred {
+				return i
+			}
+		}
+	}
+	return Max(len(p.SampleTypes)-1, 0)
+}
+
+// TopFunctions returns the flat and cumulative cost of every function for one
+// sample type, most expensive first, and the total of the sample type
+// Inlined calls count as frames of their own, as in go tool pprof
+func (p *Profile) TopFunctions(sampleIndex int, byCum bool) ([]profileFunctionCost, int64) {
+	costs := make(map[uint64]*profileFunctionCost)
+	var total int64
+	for _, sample := range p.Samples {
+		if sampleIndex >= len(sample.Values) {
+			continue
+		}
+		value := sample.Values[sampleIndex]
+		if value == 0 {
+			continue
+		}
+		total += value
+
+		seen := make(map[uint64]bool)
+		leaf := true
+		for _, locationID := range sample.LocationIDs {
+			for _, line := range p.Locations[locationID].Lines {
+				cost, exists := costs[line.FunctionID]
+				if !exists {
+					fn := p.Functions[line.FunctionID]
+					cost = &profileFunctionCost{Name: fn.Name, Filename: fn.Filename}
+					costs[line.FunctionID] = cost
+				}
+				if leaf {
+					cost.Flat += value
+					leaf = false
+				}
+				// Recursive functions count once per sample
+				if !seen[line.FunctionID] {
+					seen[line.FunctionID] = true
+					cost.Cum += value
+				}
+			}
+		}
+	}
+
+	functions := make([]profileFunctionCost, 0, len(costs))
+	for _, cost := range costs {
+		functions = append(functions, *cost)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		a, b := functions[i], functions[j]
+		if byCum && a.Cum != b.Cum {
+			return a.Cum > b.Cum
+		}
+		if a.Flat != b.Flat {
+			return a.Flat > b.Flat
+		}
+		if a.Cum != b.Cum {
+			return a.Cum > b.Cum
+		}
+		return a.Name < b.Name
+	})
+	return functions, total
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DataRace is a parsed "WARNING: DATA RACE" report from the race detector
+type DataRace struct {
+	Access     RaceAccess      // The access that triggered the report
+	Previous   RaceAccess      // The earlier access it conflicts with
+	Goroutines []RaceGoroutine // Creation sites of the goroutines involved
+	Tests      []string        // Tests the race was reported in; empty if outside any test
+	Count      int             // Times the same race was reported
+}
+
+// RaceAccess is one side of a data race: a memory access and its stack
+type RaceAccess struct {
+	Op        string // "Read", "Write", "Atomic read", ...
+	Previous  bool   // Reported as the previous access
+	Address   string
+	Goroutine int          // Goroutine that made the access, 0 for the main goroutine
+	Frames    []StackFrame // Innermost call first
+}
+
+// RaceGoroutine is a goroutine involved in a data race and where it was started
+type RaceGoroutine struct {
+	ID        int
+	State     string       // "running" or "finished"
+	CreatedAt []StackFrame // Innermost call first
+}
+
+// Label describes the access as the race detector does, e.g.
+// "Previous write at 0xc000018318 by goroutine 7"
+func (a RaceAccess) Label() string {
+	op := a.Op
+	if a.Previous {
+		op = "Previous " + strings.ToLower(op)
+	}
+	by := "main goroutine"
+	if a.Goroutine > 0 {
+		by = "goroutine " + strconv.Itoa(a.Goroutine)
+	}
+	return op + " at " + a.Address + " by " + by
+}
+
+// Location returns the location of the access's innermost frame
+func (a RaceAccess) Location() string {
+	if len(a.Frames) == 0 {
+		return ""
+	}
+	return a.Frames[0].Location()
+}
+
+// Key identifies a race by the code locations of its two accesses, so the same
+// race reported again (in another test or run, at another address) matches
+func (r DataRace) Key() string {
+	sides := []string{r.Access.Op + "@" + r.Access.Location(), r.Previous.Op + "@" + r.Previous.Location()}
+	sort.Strings(sides)
+	return strings.Join(sides, "|")
+}
+
+// Goroutine returns the creation site of the goroutine with the given id, or nil
+func (r DataRace) Goroutine(id int) *RaceGoroutine {
+	for i := range r.Goroutines {
+		if r.Goroutines[i].ID == id {
+			return &r.Goroutines[i]
+		}
+	}
+	return nil
+}
+
+// raceDelimiter surrounds every race report
+const raceDelimiter = "=================="
+
+// raceWarning is the first line of a race report
+const raceWarning = "WARNING: DATA RACE"
+
+var (
+	// raceAccessRegex matches "Previous write at 0x00c000018318 by goroutine 7:"
+	raceAccessRegex = regexp.MustCompile(`^(Previous )?((?:[Aa]tomic )?(?:[Rr]ead|[Ww]rite))(?: of size \d+)? at (0x[0-9a-f]+) by (?:goroutine (\d+)|main goroutine):$`)
+	// raceGoroutineRegex matches "Goroutine 8 (finished) created at:"
+	raceGoroutineRegex = regexp.MustCompile(`^Goroutine (\d+) \((\w+)\) created at:$`)
+	// raceLocationRegex matches "      /path/file.go:10 +0x75"
+	raceLocationRegex = regexp.MustCompile(`^\s+(\S+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// parseDataRaces parses every race report in test output
+// Repeated reports of the same race are merged
+func parseDataRaces(output string) []DataRace {
+	if !strings.Contains(output, raceWarning) {
+		return nil
+	}
+
+	var races []DataRace
+	var race *DataRace
+	var frames *[]StackFrame // Stack currently being read
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == raceWarning {
+			race = &DataRace{Count: 1}
+			frames = nil
+			continue
+		}
+		if race == nil {
+			continue
+		}
+		if trimmed == raceDelimiter {
+			races = append(races, *race)
+			race = nil
+			continue
+		}
+
+		if matches := raceAccessRegex.FindStringSubmatch(trimmed); matches != nil {
+			goroutine, _ := strconv.Atoi(matches[4])
+			access := RaceAccess{
+				Op:        strings.ToUpper(matches[2][:1]) + matches[2][1:],
+				Previous:  matches[1] != "",
+				Address:   matches[3],
+				Goroutine: goroutine,
+			}
+			if access.Previous {
+				race.Previous = access
+				frames = &race.Previous.Frames
+			} else {
+				race.Access = access
+				frames = &race.Access.Frames
+			}
+			continue
+		}
+		if matches := raceGoroutineRegex.FindStringSubmatch(trimmed); matches != nil {
+			id, _ := strconv.Atoi(matches[1])
+			race.Goroutines = append(race.Goroutines, RaceGoroutine{ID: id, State: matches[2]})
+			frames = &race.Goroutines[len(race.Goroutines)-1].CreatedAt
+			continue
+		}
+
+		if frames == nil || trimmed == "" || strings.HasPrefix(trimmed, "[") {
+			// Blank lines separate sections; "[failed to restore the stack]" has no frames
+			continue
+		}
+		if matches := raceLocationRegex.FindStringSubmatch(line); matches != nil {
+			if len(*frames) > 0 {
+				lineNum, _ := strconv.Atoi(matches[2])
+				frame := &(*frames)[len(*frames)-1]
+				frame.File = matches[1]
+				frame.Line = lineNum
+			}
+			continue
+		}
+		*frames = append(*frames, parseFrameCall(trimmed))
+	}
+
+	return dedupRaces(races)
+}
+
+// dedupRaces merges reports of the same race, summing their counts and
+// collecting the tests they were reported in, keeping first-seen order
+func dedupRaces(races []DataRace) []DataRace {
+	var deduped []DataRace
+	index := make(map[string]int)
+	for _, race := range races {
+		key := race.Key()
+		i, exists := index[key]
+		if !exists {
+			race.Tests = append([]string(nil), race.Tests...)
+			deduped = append(deduped, race)
+			index[key] = len(deduped) - 1
+			continue
+		}
+		deduped[i].Count += race.Count
+		for _, test := range race.Tests {
+			if !containsString(deduped[i].Tests, test) {
+				deduped[i].Tests = append(deduped[i].Tests, test)
+			}
+		}
+	}
+	return deduped
+}
+
+// collectRaces returns the deduplicated races of a package: those of its tests
+// plus the races in races that weren't reported by a test
+func collectRaces(tests []TestResult, races []DataRace) []DataRace {
+	var all []DataRace
+	for _, test := range tests {
+		all = append(all, test.Races...)
+	}
+	for _, race := range races {
+		if len(race.Tests) == 0 {
+			all = append(all, race)
+		}
+	}
+	return dedupRaces(all)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
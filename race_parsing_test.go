@@ -0,0 +1,156 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// raceReport builds the output of one race report between a write in write.go
+// and a read in read.go at the given lines
+func raceReport(address string, goroutine, writeLine, readLine int) string {
+	return strings.Join([]string{
+		"==================",
+		"WARNING: DATA RACE",
+		"Write at " + address + " by goroutine " + strconv.Itoa(goroutine) + ":",
+		"  example.com/app.(*Counter).Inc()",
+		"      /src/app/write.go:" + strconv.Itoa(writeLine) + " +0x44",
+		"  example.com/app.TestCounter.func1()",
+		"      /src/app/app_test.go:15 +0x2e",
+		"",
+		"Previous read at " + address + " by main goroutine:",
+		"  example.com/app.(*Counter).Get()",
+		"      /src/app/read.go:" + strconv.Itoa(readLine) + " +0x3a",
+		"",
+		"Goroutine " + strconv.Itoa(goroutine) + " (running) created at:",
+		"  example.com/app.TestCounter()",
+		"      /src/app/app_test.go:14 +0xbc",
+		"  testing.tRunner()",
+		"      /usr/local/go/src/testing/testing.go:1689 +0x21e",
+		"==================",
+		"",
+	}, "\n")
+}
+
+func TestParseDataRaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		wantCount []int // Count of every race, in order
+	}{
+		{
+			name:   "no race",
+			output: "=== RUN   TestCounter\n--- PASS: TestCounter (0.00s)\n",
+		},
+		{
+			name:      "single race",
+			output:    raceReport("0x00c000018318", 7, 10, 20),
+			wantCount: []int{1},
+		},
+		{
+			name: "same race at another address and goroutine",
+			output: raceReport("0x00c000018318", 7, 10, 20) +
+				"    testing.go:1398: race detected during execution of test\n" +
+				raceReport("0x00c0000a4010", 9, 10, 20),
+			wantCount: []int{2},
+		},
+		{
+			name:      "different races",
+			output:    raceReport("0x00c000018318", 7, 10, 20) + raceReport("0x00c000018318", 7, 11, 20),
+			wantCount: []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			races := parseDataRaces(tt.output)
+			if len(races) != len(tt.wantCount) {
+				t.Fatalf("got %d races, want %d: %+v", len(races), len(tt.wantCount), races)
+			}
+			for i, count := range tt.wantCount {
+				if races[i].Count != count {
+					t.Errorf("race %d Count = %d, want %d", i, races[i].Count, count)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDataRaceFields(t *testing.T) {
+	races := parseDataRaces(raceReport("0x00c000018318", 7, 10, 20))
+	if len(races) != 1 {
+		t.Fatalf("got %d races, want 1", len(races))
+	}
+	race := races[0]
+
+	if got, want := race.Access.Label(), "Write at 0x00c000018318 by goroutine 7"; got != want {
+		t.Errorf("Access.Label() = %q, want %q", got, want)
+	}
+	if got, want := race.Previous.Label(), "Previous read at 0x00c000018318 by main goroutine"; got != want {
+		t.Errorf("Previous.Label() = %q, want %q", got, want)
+	}
+	if got, want := race.Access.Location(), "/src/app/write.go:10"; got != want {
+		t.Errorf("Access.Location() = %q, want %q", got, want)
+	}
+	if got, want := len(race.Access.Frames), 2; got != want {
+		t.Errorf("Access has %d frames, want %d", got, want)
+	}
+	goroutine := race.Goroutine(7)
+	if goroutine == nil {
+		t.Fatal("Goroutine(7) = nil, want its creation site")
+	}
+	if goroutine.State != "running" || len(goroutine.CreatedAt) != 2 || goroutine.CreatedAt[0].Function != "example.com/app.TestCounter" {
+		t.Errorf("Goroutine(7) = %+v, want running, created at example.com/app.TestCounter", goroutine)
+	}
+}
+
+func TestCollectRaces(t *testing.T) {
+	race := parseDataRaces(raceReport("0x00c000018318", 7, 10, 20))[0]
+	inTest := func(name string) DataRace {
+		r := race
+		r.Tests = []string{name}
+		return r
+	}
+
+	tests := []struct {
+		name      string
+		tests     []TestResult
+		races     []DataRace
+		wantCount int
+		wantTests []string
+	}{
+		{
+			name:      "same race in two tests",
+			tests:     []TestResult{{Name: "TestA", Races: []DataRace{inTest("TestA")}}, {Name: "TestB", Races: []DataRace{inTest("TestB")}}},
+			wantCount: 2,
+			wantTests: []string{"TestA", "TestB"},
+		},
+		{
+			name:      "package-level report of a test's race is dropped",
+			tests:     []TestResult{{Name: "TestA", Races: []DataRace{inTest("TestA")}}},
+			races:     []DataRace{inTest("TestA")},
+			wantCount: 1,
+			wantTests: []string{"TestA"},
+		},
+		{
+			name:      "race outside any test",
+			races:     []DataRace{race},
+			wantCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			races := collectRaces(tt.tests, tt.races)
+			if len(races) != 1 {
+				t.Fatalf("got %d races, want 1: %+v", len(races), races)
+			}
+			if races[0].Count != tt.wantCount {
+				t.Errorf("Count = %d, want %d", races[0].Count, tt.wantCount)
+			}
+			if strings.Join(races[0].Tests, ",") != strings.Join(tt.wantTests, ",") {
+				t.Errorf("Tests = %v, want %v", races[0].Tests, tt.wantTests)
+			}
+		})
+	}
+}
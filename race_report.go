@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RaceReportState holds the state for the data race view
+type RaceReportState struct {
+	packageName string
+	selected    int // Index of the race shown in detail
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+func newRaceReportState(packageName string, returnTo appScreen) RaceReportState {
+	return RaceReportState{
+		packageName: packageName,
+		returnTo:    returnTo,
+	}
+}
+
+// FormatRaceReport renders the deduplicated races of a package: one line per race,
+// followed by the selected race with its two accesses and the creation sites of
+// their goroutines side by side
+// Returns the content and the line of the selected race in the list
+func FormatRaceReport(races []DataRace, theme Theme, state RaceReportState, width int) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(failStyle.Render(fmt.Sprintf("%d data race(s) detected", len(races))) + "\n\n")
+
+	cursorLine := 0
+	for i, race := range races {
+		line := fmt.Sprintf("%2d. %s %s ↔ %s %s", i+1,
+			strings.ToLower(race.Access.Op), shortLocation(race.Access.Location()),
+			strings.ToLower(race.Previous.Op), shortLocation(race.Previous.Location()))
+		if race.Count > 1 {
+			line += fmt.Sprintf("  ×%d", race.Count)
+		}
+		if len(race.Tests) > 0 {
+			line += "  " + strings.Join(race.Tests, ", ")
+		}
+		if i == state.selected {
+			cursorLine = strings.Count(output.String(), "\n")
+			output.WriteString(selectedStyle.Render(line) + "\n")
+		} else {
+			output.WriteString(normalStyle.Render(line) + "\n")
+		}
+	}
+
+	if state.selected < 0 || state.selected >= len(races) {
+		return output.String(), cursorLine
+	}
+	race := races[state.selected]
+
+	// Two columns separated by " │ "
+	columnWidth := (width - 3) / 2
+	if columnWidth < 20 {
+		columnWidth = 20
+	}
+	output.WriteString("\n" + separatorStyle.Render(strings.Repeat("─", columnWidth*2+3)) + "\n")
+
+	output.WriteString(sideBySide(
+		formatRaceStack(race.Access.Label(), race.Access.Frames, failStyle, theme),
+		formatRaceStack(race.Previous.Label(), race.Previous.Frames, failStyle, theme),
+		columnWidth, separatorStyle) + "\n")
+
+	// Where the goroutines making the two accesses were started
+	left := formatRaceGoroutine(race, race.Access.Goroutine, theme)
+	right := formatRaceGoroutine(race, race.Previous.Goroutine, theme)
+	if left != "" || right != "" {
+		output.WriteString("\n" + sideBySide(left, right, columnWidth, separatorStyle) + "\n")
+	}
+
+	return output.String(), cursorLine
+}
+
+// formatRaceStack renders a titled stack as one column of the race view
+func formatRaceStack(title string, frames []StackFrame, titleStyle lipgloss.Style, theme Theme) string {
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+
+	lines := []string{titleStyle.Render(title)}
+	for _, frame := range frames {
+		lines = append(lines, metricStyle.Render("  "+frame.Function+"()"))
+		lines = append(lines, helpStyle.Render("      "+shortLocation(frame.Location())))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatRaceGoroutine renders the creation site of one of a race's goroutines
+// Returns "" if the report doesn't include it (e.g. the main goroutine)
+func formatRaceGoroutine(race DataRace, id int, theme Theme) string {
+	goroutine := race.Goroutine(id)
+	if goroutine == nil {
+		return ""
+	}
+	titleStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	title := fmt.Sprintf("Goroutine %d (%s) created at:", goroutine.ID, goroutine.State)
+	return formatRaceStack(title, goroutine.CreatedAt, titleStyle, theme)
+}
+
+// sideBySide joins two blocks into columns of the given width
+func sideBySide(left, right string, columnWidth int, separatorStyle lipgloss.Style) string {
+	columnStyle := lipgloss.NewStyle().Width(columnWidth).MaxWidth(columnWidth)
+	leftBlock := columnStyle.Render(left)
+	rightBlock := columnStyle.Render(right)
+
+	height := Max(lipgloss.Height(leftBlock), lipgloss.Height(rightBlock))
+	separator := strings.TrimSuffix(strings.Repeat(separatorStyle.Render(" │ ")+"\n", height), "\n")
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, leftBlock, separator, rightBlock)
+}
+
+// shortLocation shortens a file:line location to the file's directory and name
+func shortLocation(location string) string {
+	if location == "" {
+		return "?"
+	}
+	dir, file := filepath.Split(location)
+	return filepath.Join(filepath.Base(dir), file)
+}
@@ -1,52 +1,100 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // TestPackage represents a Go package with tests
 type TestPackage struct {
-	Name                 string
-	Path                 string
-	TestFiles            []string
-	HasIntegrationTests  bool
+	Name                string
+	Path                string
+	TestFiles           []string
+	HasIntegrationTests bool
+	TagSets             [][]string // Distinct build tag sets required by tagged test files, each sorted
+	TestFuncs           []TestFunc // Top-level test functions and the files they are declared in
 }
 
-// hasIntegrationBuildTag checks if a file has integration test build tags
-func hasIntegrationBuildTag(filePath string) bool {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return false
+// TestFunc is a top-level Test, Benchmark, Example or Fuzz function found in a test file
+type TestFunc struct {
+	Name       string
+	File       string          // Test file name within the package directory
+	Tags       []string        // Minimal custom tags the file needs ("" set = unit)
+	constraint constraint.Expr // Build constraint of the file (nil = always built)
+}
+
+// TestType returns the test type of tests declared by the function
+func (f TestFunc) TestType() string {
+	if len(f.Tags) == 0 {
+		return "unit"
 	}
-	defer file.Close()
+	return strings.Join(f.Tags, ",")
+}
 
-	scanner := bufio.NewScanner(file)
-	lineCount := 0
-	// Only check first 10 lines (build tags must be at top of file)
-	for scanner.Scan() && lineCount < 10 {
-		line := strings.TrimSpace(scanner.Text())
-		lineCount++
+// testFuncPrefixes are the function name prefixes go test runs
+var testFuncPrefixes = []string{"Test", "Benchmark", "Example", "Fuzz"}
 
-		// Check for both old and new build tag formats
-		if strings.Contains(line, "//go:build") && strings.Contains(line, "integration") {
-			return true
+// isTestFuncName reports whether name is a function go test treats as a test
+// The prefix must be followed by the end of the name or a non-lowercase letter
+func isTestFuncName(name string) bool {
+	for _, prefix := range testFuncPrefixes {
+		if !strings.HasPrefix(name, prefix) {
+			continue
 		}
-		if strings.Contains(line, "// +build") && strings.Contains(line, "integration") {
+		rest := name[len(prefix):]
+		if rest == "" {
 			return true
 		}
+		r, _ := utf8.DecodeRuneInString(rest)
+		return !unicode.IsLower(r)
+	}
+	return false
+}
+
+// parseTestFuncs lists the top-level test functions declared in a test file
+func parseTestFuncs(filePath string) ([]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
 
-		// Stop at package declaration
-		if strings.HasPrefix(line, "package ") {
-			break
+	var names []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+		if isTestFuncName(fn.Name.Name) {
+			names = append(names, fn.Name.Name)
 		}
 	}
+	return names, nil
+}
 
-	return false
+// BuildTags returns every custom build tag used by the package's test files, sorted
+func (p TestPackage) BuildTags() []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, set := range p.TagSets {
+		for _, tag := range set {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
 }
 
 // ScanForTests recursively scans a directory for *_test.go files
@@ -79,10 +127,36 @@ func ScanForTests(rootPath string) ([]TestPackage, error) {
 			}
 			packages[dir].TestFiles = append(packages[dir].TestFiles, info.Name())
 
-			// Check if this test file has integration build tags
-			if hasIntegrationBuildTag(path) {
-				packages[dir].HasIntegrationTests = true
-				LogDebug("Found integration tests", "package", relDir, "file", info.Name())
+			// Record the build tags this test file needs
+			expr := readBuildConstraint(path)
+			tagSet, buildable := minimalTagSet(expr)
+			if !buildable {
+				LogDebug("Test file not built on this platform", "package", relDir, "file", info.Name())
+				return nil
+			}
+
+			// Map the file's test functions to it so test types are known without running
+			funcNames, parseErr := parseTestFuncs(path)
+			if parseErr != nil {
+				LogWarn("Failed to parse test file", "package", relDir, "file", info.Name(), "error", parseErr)
+			}
+			for _, name := range funcNames {
+				packages[dir].TestFuncs = append(packages[dir].TestFuncs, TestFunc{
+					Name:       name,
+					File:       info.Name(),
+					Tags:       tagSet,
+					constraint: expr,
+				})
+			}
+
+			if len(tagSet) > 0 {
+				packages[dir].TagSets = addTagSet(packages[dir].TagSets, tagSet)
+				for _, tag := range tagSet {
+					if tag == "integration" {
+						packages[dir].HasIntegrationTests = true
+					}
+				}
+				LogDebug("Found tagged tests", "package", relDir, "file", info.Name(), "tags", strings.Join(tagSet, ","))
 			}
 		}
 
@@ -97,6 +171,9 @@ func ScanForTests(rootPath string) ([]TestPackage, error) {
 	result := make([]TestPackage, 0, len(packages))
 	for _, pkg := range packages {
 		sort.Strings(pkg.TestFiles)
+		sort.Slice(pkg.TagSets, func(i, j int) bool {
+			return strings.Join(pkg.TagSets[i], ",") < strings.Join(pkg.TagSets[j], ",")
+		})
 		result = append(result, *pkg)
 	}
 
@@ -114,7 +191,9 @@ func ScanForTests(rootPath string) ([]TestPackage, error) {
 }
 
 // RenderTestTree creates a visual tree representation of test packages
-func RenderTestTree(packages []TestPackage, selectedIndex int, theme Theme) string {
+// statuses maps package name to its current status (RUNNING, QUEUED, PASS, ...)
+// failureKinds maps failed packages to why they failed, shown after the status
+func RenderTestTree(packages []TestPackage, selectedIndex int, theme Theme, statuses map[string]string, failureKinds map[string]FailureKind) string {
 	if len(packages) == 0 {
 		return "No test files found.\n\nRun from a Go project directory."
 	}
@@ -139,10 +218,19 @@ func RenderTestTree(packages []TestPackage, selectedIndex int, theme Theme) stri
 			pkgName = "."
 		}
 
-		// Add [i] indicator if package has integration tests
+		// Add [i] indicator if package has integration tests, and list other build tags
 		if pkg.HasIntegrationTests {
 			pkgName += " [i]"
 		}
+		var otherTags []string
+		for _, tag := range pkg.BuildTags() {
+			if tag != "integration" {
+				otherTags = append(otherTags, tag)
+			}
+		}
+		if len(otherTags) > 0 {
+			pkgName += " [" + strings.Join(otherTags, ",") + "]"
+		}
 
 		sb.WriteString(treeStyle.Render(prefix))
 
@@ -160,8 +248,45 @@ func RenderTestTree(packages []TestPackage, selectedIndex int, theme Theme) stri
 		}
 
 		sb.WriteString(treeStyle.Render(filePrefix) +
-			testCountStyle(theme).Render(fmt.Sprintf("  (%d tests)", len(pkg.TestFiles))) + "\n")
+			testCountStyle(theme).Render(fmt.Sprintf("  (%d tests)", len(pkg.TestFiles))))
+		if targets := len(pkg.FuzzTargets()); targets > 0 {
+			sb.WriteString(testCountStyle(theme).Render(fmt.Sprintf(" [%d fuzz]", targets)))
+		}
+		// Show status so active and finished packages stand out during parallel runs
+		if status, exists := statuses[pkg.Name]; exists {
+			sb.WriteString(" " + styledStatus(status, theme))
+			if kind, failed := failureKinds[pkg.Name]; failed {
+				sb.WriteString(" " + failureKindStyle.Render(string(kind)))
+			}
+		}
+		sb.WriteString("\n")
 	}
 
 	return sb.String()
 }
+
+// findModulePath returns the module path declared by the go.mod governing dir,
+// looking in dir and its parents
+// Returns "" if no go.mod is found
+func findModulePath(dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if data, err := os.ReadFile(filepath.Join(absDir, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) >= 2 && fields[0] == "module" {
+					return strings.Trim(fields[1], `"`)
+				}
+			}
+			return ""
+		}
+		parent := filepath.Dir(absDir)
+		if parent == absDir {
+			return ""
+		}
+		absDir = parent
+	}
+}
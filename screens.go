@@ -88,7 +88,7 @@ func (m *model) renderMainScreen() string {
 	if m.scanError != nil {
 		leftContent = fmt.Sprintf("Scan Error\n\nFailed to scan for test packages.\n\nPath: %s\n\nError:\n%v\n\nPlease check the path and try again.", m.scanPath, m.scanError)
 	} else {
-		leftContent = RenderTestTree(m.testPackages, m.selectedIndex, m.currentTheme)
+		leftContent = RenderTestTree(m.testPackages, m.selectedIndex, m.currentTheme, m.packageStatuses(), m.packageFailureKinds())
 	}
 
 	// Right panel content - show test results if available
@@ -97,7 +97,16 @@ func (m *model) renderMainScreen() string {
 		selectedPkg := m.testPackages[m.selectedIndex]
 		// Check if test is currently running
 		if running, exists := m.testsRunning[selectedPkg.Name]; exists && running {
-			rightContent = fmt.Sprintf("Running tests...\n\nPackage: %s\n\nPlease wait while tests execute.\nThis may take a few moments for larger test suites.", selectedPkg.Name)
+			if live, hasLive := m.liveTests[selectedPkg.Name]; hasLive {
+				// Show results streamed so far
+				if m.rightPanelView == viewDetails {
+					rightContent = FormatTestResult(live.result, m.modulePath, m.currentTheme, m.selectedTestName, m.expandedTests[selectedPkg.Name])
+				} else {
+					rightContent = FormatTestResultSummary(live.result, m.currentTheme, m.summaryButtonIndex)
+				}
+			} else {
+				rightContent = fmt.Sprintf("Running tests...\n\nPackage: %s\n\nPlease wait while tests execute.\nThis may take a few moments for larger test suites.", selectedPkg.Name)
+			}
 		} else if err, exists := m.testErrors[selectedPkg.Name]; exists {
 			// Check for errors
 			rightContent = fmt.Sprintf("Test Error\n\nFailed to run tests for package: %s\n\nError:\n%v", selectedPkg.Name, err)
@@ -107,7 +116,7 @@ func (m *model) renderMainScreen() string {
 			case viewSummary:
 				rightContent = FormatTestResultSummary(result, m.currentTheme, m.summaryButtonIndex)
 			case viewDetails:
-				rightContent = FormatTestResult(result, m.currentTheme)
+				rightContent = FormatTestResult(result, m.modulePath, m.currentTheme, m.selectedTestName, m.expandedTests[selectedPkg.Name])
 			case viewCoverageGaps:
 				rightContent = FormatCoverageGaps(result, m.currentTheme)
 			default:
@@ -169,9 +178,12 @@ func (m *model) renderMainScreen() string {
 	} else {
 		modeIndicator = fmt.Sprintf("Mode: %s", m.currentTestMode)
 	}
+	if len(m.testsRunning) > 0 || m.runAllInProgress {
+		modeIndicator += fmt.Sprintf(" | Running: %d/%d (%d queued)", len(m.testsRunning), m.parallelLimit(), len(m.testQueue))
+	}
 	var helpText string
 	if m.currentFocus == focusLeftPanel {
-		helpText = fmt.Sprintf("%s | `: menu | ↑↓/jk: navigate | f: fullscreen | Tab: switch panel | ]/[: resize | t: theme (%s) | q: quit", modeIndicator, m.currentTheme.Name)
+		helpText = fmt.Sprintf("%s | `: menu | ↑↓/jk: navigate | f: fullscreen | x/X: cancel | Tab: switch panel | ]/[: resize | t: theme (%s) | q: quit", modeIndicator, m.currentTheme.Name)
 	} else {
 		// In right panel - show context-specific help
 		if m.rightPanelView == viewCoverageGaps {
@@ -179,9 +191,12 @@ func (m *model) renderMainScreen() string {
 		} else if m.rightPanelView == viewSummary {
 			helpText = fmt.Sprintf("%s | `: menu | Enter: select | Tab: switch panel | ]/[: resize | t: theme (%s) | q: quit", modeIndicator, m.currentTheme.Name)
 		} else {
-			helpText = fmt.Sprintf("%s | `: menu | ↑↓/jk: scroll | Tab: switch panel | ]/[: resize | t: theme (%s) | q: quit", modeIndicator, m.currentTheme.Name)
+			helpText = fmt.Sprintf("%s | `: menu | ↑↓/jk: scroll | n/p: select test | Space/e: fold subtests | r/R: run test (+/- subtests) | Tab: switch panel | t: theme (%s) | q: quit", modeIndicator, m.currentTheme.Name)
 		}
 	}
+	if m.statusNotice != "" {
+		helpText = m.statusNotice + " | " + helpText
+	}
 	help := helpStyle.Render(helpText)
 
 	// Combine everything
@@ -194,7 +209,7 @@ func (m model) renderTestsMenu() string {
 	// Show current mode in header
 	modeDisplay := string(m.currentTestMode)
 	header := m.headerStyle().Render("═══ TESTS MENU ═══") + "\n" +
-		m.helpBarStyle().Render("Current Mode: "+modeDisplay)
+		m.helpBarStyle().Render(fmt.Sprintf("Current Mode: %s | Parallel Packages: %d", modeDisplay, m.parallelLimit()))
 
 	// Content
 	contentStyle := m.contentAreaStyle(contentHeight - 3)
@@ -203,6 +218,28 @@ func (m model) renderTestsMenu() string {
 	content += boldSectionHeader().Render("Test Options") + "\n\n"
 
 	for i, item := range m.testsMenuItems {
+		// Show the current value of toggle items
+		if i == 3 {
+			item += fmt.Sprintf(" [%s]", m.executionStrategy())
+		}
+		if i == 4 && m.selectedIndex < len(m.testPackages) {
+			profile := m.getFlagProfileForPath(m.testPackages[m.selectedIndex].Path)
+			if profile.IsEmpty() {
+				item += " [default]"
+			} else {
+				item += fmt.Sprintf(" [%s]", profile.String())
+			}
+		}
+		if i == 5 {
+			item += fmt.Sprintf(" [x%d]", m.config.FlakyRunCount)
+		}
+		if i == 9 {
+			if setting := m.coverPkgSetting(); setting != "" {
+				item += fmt.Sprintf(" [%s]", setting)
+			} else {
+				item += " [off]"
+			}
+		}
 		if i == m.testsMenuIndex {
 			content += m.selectedItemStyle().Render(" > "+item+" ") + "\n"
 		} else {
@@ -210,11 +247,74 @@ func (m model) renderTestsMenu() string {
 		}
 	}
 
+	// Compare the last Know It All run of each execution strategy
+	if len(m.runAllDurations) > 0 {
+		content += "\n" + boldSectionHeader().Render("Last Know It All Run") + "\n\n"
+		for _, strategy := range []executionStrategy{strategyPerPackage, strategySingle} {
+			if duration, exists := m.runAllDurations[strategy]; exists {
+				content += m.normalItemStyle().Render(fmt.Sprintf("%-12s %s", strategy, formatDuration(duration))) + "\n"
+			}
+		}
+	}
+
 	footer := m.helpBarStyle().Render("↑↓/jk: navigate | Enter: select | ESC: back")
 
 	return lipgloss.JoinVertical(lipgloss.Left, header, contentStyle.Render(content), footer)
 }
 
+func (m model) renderFlagProfileEditor() string {
+	contentHeight := m.height - MenuBarH
+	editor := m.flagProfileEditor
+
+	header := m.headerStyle().Render("═══ FLAG PROFILE ═══") + "\n" +
+		m.helpBarStyle().Render(fmt.Sprintf("Package: %s | Directory: %s", editor.packageName, editor.dirPath))
+
+	// Content
+	contentStyle := m.contentAreaStyle(contentHeight - 3)
+	descStyle := lipgloss.NewStyle().Foreground(m.currentTheme.HelpColor)
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+
+	var content string
+	content += boldSectionHeader().Render("go test Settings") + "\n\n"
+
+	for i, field := range editor.fields {
+		value := field.GetValue(&editor.profile)
+		if i == editor.selectedField && editor.editing {
+			value = editor.input + "█"
+		} else if value == "" {
+			value = "(none)"
+		}
+		line := fmt.Sprintf("%-14s %s", field.Name, value)
+		if i == editor.selectedField {
+			content += m.selectedItemStyle().Render(" > "+line+" ") + "\n"
+			content += descStyle.Render("     "+field.Description) + "\n"
+		} else {
+			content += m.normalItemStyle().Render("   "+line) + "\n"
+		}
+	}
+
+	if editor.inputError != "" {
+		content += "\n" + errorStyle.Render("Error: "+editor.inputError) + "\n"
+	}
+
+	// Show the flags go test will receive
+	content += "\n" + boldSectionHeader().Render("Applied Flags") + "\n\n"
+	if editor.profile.IsEmpty() {
+		content += m.normalItemStyle().Render("   (defaults)") + "\n"
+	} else {
+		content += m.normalItemStyle().Render("   "+editor.profile.String()) + "\n"
+	}
+
+	var footer string
+	if editor.editing {
+		footer = m.helpBarStyle().Render("Type value | Enter: save | Ctrl+U: clear | ESC: cancel")
+	} else {
+		footer = m.helpBarStyle().Render("↑↓/jk: navigate | Enter: edit/toggle | d: reset field | ESC: back")
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, contentStyle.Render(content), footer)
+}
+
 func (m model) renderTestModeSelection() string {
 	contentHeight := m.height - MenuBarH
 
@@ -226,28 +326,26 @@ func (m model) renderTestModeSelection() string {
 	var content string
 	content += boldSectionHeader().Render("Select Test Mode") + "\n\n"
 
+	// Mark the mode the selected package currently uses
+	currentMode := m.currentTestMode
+	if m.selectedIndex < len(m.testPackages) {
+		currentMode = m.getTestModeForPath(m.testPackages[m.selectedIndex].Path)
+	}
+
 	for i, item := range m.testModeItems {
-		var line string
-		if i == m.testModeIndex {
-			line = " > " + item
-			if i == 0 && m.currentTestMode == testModeUnit {
-				line += " [current]"
-			} else if i == 1 && m.currentTestMode == testModeIntegration {
-				line += " [current]"
-			} else if i == 2 && m.currentTestMode == testModeAll {
-				line += " [current]"
+		line := item.Label()
+		if item == testModeAll && m.selectedIndex < len(m.testPackages) {
+			if tags := m.testPackages[m.selectedIndex].BuildTags(); len(tags) > 0 {
+				line += " (untagged + " + strings.Join(tags, ",") + ")"
 			}
-			content += m.selectedItemStyle().Render(line) + "\n"
+		}
+		if item == currentMode {
+			line += " [current]"
+		}
+		if i == m.testModeIndex {
+			content += m.selectedItemStyle().Render(" > "+line) + "\n"
 		} else {
-			line = "   " + item
-			if i == 0 && m.currentTestMode == testModeUnit {
-				line += " [current]"
-			} else if i == 1 && m.currentTestMode == testModeIntegration {
-				line += " [current]"
-			} else if i == 2 && m.currentTestMode == testModeAll {
-				line += " [current]"
-			}
-			content += m.normalItemStyle().Render(line) + "\n"
+			content += m.normalItemStyle().Render("   "+line) + "\n"
 		}
 	}
 
@@ -448,6 +546,10 @@ func (m model) renderHelp() string {
 	content += sectionStyle.Render("═══ TEST PACKAGES ═══") + "\n"
 	content += keyStyle.Render("  ↑↓ / j k  ") + " - Navigate test package list\n"
 	content += keyStyle.Render("  Enter     ") + " - Run tests for selected package\n"
+	content += keyStyle.Render("  x         ") + " - Cancel tests for selected package\n"
+	content += keyStyle.Render("  X         ") + " - Cancel all running tests (stops Know It All)\n"
+	content += keyStyle.Render("  b         ") + " - Benchmarks of selected package (runs them the first time)\n"
+	content += keyStyle.Render("  z         ") + " - Fuzz targets of selected package\n"
 	content += keyStyle.Render("  ] / [     ") + " - Widen / narrow left panel\n\n"
 
 	// Test Results Navigation
@@ -459,6 +561,19 @@ func (m model) renderHelp() string {
 	content += keyStyle.Render("  PgUp      ") + " - Scroll up one page\n"
 	content += keyStyle.Render("  PgDn      ") + " - Scroll down one page\n"
 	content += keyStyle.Render("  Enter     ") + " - Select button (TEST DETAILS / COVERAGE GAPS)\n"
+	content += keyStyle.Render("  n / p     ") + " - Select next / previous test in test details\n"
+	content += keyStyle.Render("  Space     ") + " - Expand / collapse the subtests of selected test\n"
+	content += keyStyle.Render("  e         ") + " - Expand / collapse all subtests\n"
+	content += keyStyle.Render("  r         ") + " - Run selected test (with subtests)\n"
+	content += keyStyle.Render("  R         ") + " - Run selected test (without subtests)\n"
+	content += keyStyle.Render("  d         ") + " - Browse goroutine dump of a timed out run\n"
+	content += keyStyle.Render("  D         ") + " - View data races of a -race run side by side\n"
+	content += keyStyle.Render("  P         ") + " - View the stack of a panicking test\n"
+	content += keyStyle.Render("  B         ") + " - View build errors with source context\n"
+	content += keyStyle.Render("  T         ") + " - Top functions of a profiled run's CPU and memory profiles\n"
+	content += keyStyle.Render("  L         ") + " - Timeline of the last run: tests over wall-clock time\n"
+	content += keyStyle.Render("  C         ") + " - Per-file coverage; Enter shows a file's source with its coverage\n"
+	content += keyStyle.Render("  s         ") + " - Replay a shuffled run with the same seed\n"
 	content += keyStyle.Render("  f         ") + " - Full-screen mode (shows highlighted view)\n"
 	content += keyStyle.Render("  ESC       ") + " - Return to summary view\n\n"
 
@@ -523,9 +638,13 @@ func (m model) renderFullTestResults() string {
 		// No results - should not happen but handle gracefully
 		return m.borderedContentStyle().Render("No test results available\n\nPress ESC to return")
 	}
+	// While a selected test reruns, show it streaming in
+	if live, running := m.liveTests[m.fullScreenPackage]; running {
+		result = live.result
+	}
 
 	// Generate full test output
-	fullContent := FormatTestResult(result, m.currentTheme)
+	fullContent := FormatTestResult(result, m.modulePath, m.currentTheme, m.selectedTestName, m.expandedTests[m.fullScreenPackage])
 
 	// Handle scrolling
 	contentLines := strings.Split(fullContent, "\n")
@@ -561,11 +680,311 @@ func (m model) renderFullTestResults() string {
 
 	// Add help bar
 	helpStyle := m.helpBarStyle()
-	helpText := helpStyle.Render("↑↓/jk: scroll | g/G: top/bottom | PgUp/PgDn: page | ESC: return | q: quit")
+	helpText := helpStyle.Render("↑↓/jk: scroll | g/G: top/bottom | PgUp/PgDn: page | n/p: next/prev test | Space/e: fold subtests | r/R: run test (+/- subtests) | ESC: return | q: quit")
 
 	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
 }
 
+func (m model) renderGoroutineDump() string {
+	contentHeight := m.height - MenuBarH
+
+	result, exists := m.testResults[m.goroutineDump.packageName]
+	if !exists || result.Timeout == nil {
+		return m.borderedContentStyle().Render("No goroutine dump available\n\nPress ESC to return")
+	}
+
+	content, _ := FormatGoroutineDump(result.Timeout, m.currentTheme, m.goroutineDump)
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.goroutineDump.scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select goroutine | Enter: expand/collapse | e/c: expand/collapse all | ESC: return", m.goroutineDump.packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderRaceReport() string {
+	contentHeight := m.height - MenuBarH
+
+	result, exists := m.testResults[m.raceReport.packageName]
+	if !exists || len(result.Races) == 0 {
+		return m.borderedContentStyle().Render("No data races reported\n\nPress ESC to return")
+	}
+
+	content, _ := FormatRaceReport(result.Races, m.currentTheme, m.raceReport, m.width-6)
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.raceReport.scroll
+	if maxStart := len(contentLines) - visibleLines; start > maxStart {
+		start = Max(maxStart, 0)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select race | PgUp/PgDn: scroll | ESC: return", m.raceReport.packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderPanicStack() string {
+	contentHeight := m.height - MenuBarH
+
+	result, exists := m.testResults[m.panicStack.packageName]
+	if !exists || result.Panic == nil {
+		return m.borderedContentStyle().Render("No panic recorded\n\nPress ESC to return")
+	}
+
+	content, _ := FormatPanicStack(result.Panic, m.modulePath, m.currentTheme, m.panicStack)
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.panicStack.scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select frame | Enter: expand/fold runtime frames | e: expand/fold all | ESC: return", m.panicStack.packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderBuildErrors() string {
+	contentHeight := m.height - MenuBarH
+
+	result, exists := m.testResults[m.buildErrorView.packageName]
+	if !exists || len(result.BuildErrors) == 0 {
+		return m.borderedContentStyle().Render("No build errors recorded\n\nPress ESC to return")
+	}
+
+	content, _ := FormatBuildErrors(result.BuildErrors, m.currentTheme, m.buildErrorView)
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.buildErrorView.scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select error | g/G: first/last | ESC: return", m.buildErrorView.packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderBenchmarks() string {
+	contentHeight := m.height - MenuBarH
+
+	packageName := m.benchmarkView.packageName
+	result, exists := m.benchmarkViewResult(packageName)
+	if !exists {
+		message := "No benchmark results for " + packageName + "\n\nPress r to run the benchmarks or ESC to return"
+		if err, failed := m.testErrors[packageName]; failed {
+			message = fmt.Sprintf("Error running benchmarks for %s:\n%v\n\nPress r to retry or ESC to return", packageName, err)
+		}
+		return m.borderedContentStyle().Render(message)
+	}
+
+	content, _ := FormatBenchmarks(result, m.currentTheme, m.benchmarkView, m.width-6)
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.benchmarkView.scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select | ←→/hl: column | s: sort | r: run again | x: cancel | S: snapshot | c: compare | ESC: return", packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderBenchmarkCompare() string {
+	contentHeight := m.height - MenuBarH
+
+	packageName := m.benchmarkCompare.packageName
+	result, exists := m.benchmarkResults[packageName]
+	if !exists {
+		return m.borderedContentStyle().Render("No finished benchmark run for " + packageName + "\n\nPress ESC to return")
+	}
+
+	content, _ := FormatBenchmarkComparison(result, m.currentTheme, m.benchmarkCompare, m.width-6)
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.benchmarkCompare.scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select | ←→/hl: unit | n/p: next/previous baseline | ESC: return", packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderFuzz() string {
+	contentHeight := m.height - MenuBarH
+
+	packageName := m.fuzzView.packageName
+	pkg, found := findTestPackage(m.testPackages, packageName)
+	if !found {
+		return m.borderedContentStyle().Render("Package " + packageName + " is no longer in the scan\n\nPress ESC to return")
+	}
+
+	content, _ := FormatFuzz(packageName, pkg.FuzzTargets(), m.fuzzViewResults(pkg), m.currentTheme, m.fuzzView, m.width-6)
+	if err, failed := m.testErrors[packageName]; failed {
+		content += fmt.Sprintf("\nError running go test: %v\n", err)
+	}
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.fuzzView.scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select | ←→/hl: fuzz time | Enter: fuzz | x: cancel | t: rerun failing input as test | PgUp/PgDn: scroll | ESC: return", packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderProfile() string {
+	contentHeight := m.height - MenuBarH
+
+	packageName := m.profileView.packageName
+	result, exists := m.testResults[packageName]
+	if !exists {
+		return m.borderedContentStyle().Render("No profiled run for " + packageName + "\n\nPress ESC to return")
+	}
+
+	content, _ := FormatProfile(result, m.currentTheme, m.profileView, m.width-6)
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.profileView.scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select | c/m: CPU/memory | ←→/hl: sample type | s: sort flat/cum | ESC: return", packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderTimeline() string {
+	contentHeight := m.height - MenuBarH
+
+	packageName := m.timelineView.packageName
+	result, exists := m.testResults[packageName]
+	if !exists {
+		return m.borderedContentStyle().Render("No test results for " + packageName + "\n\nPress ESC to return")
+	}
+
+	content, _ := FormatTimeline(result, m.currentTheme, m.timelineView, m.width-6)
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := m.timelineView.scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	helpText := m.helpBarStyle().Render(fmt.Sprintf("%s | ↑↓/jk: select test | g/G: first/last | PgUp/PgDn: page | ESC: return", packageName))
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + helpText
+}
+
+func (m model) renderCoverage() string {
+	contentHeight := m.height - MenuBarH
+
+	packageName := m.coverageView.packageName
+	result, exists := m.testResults[packageName]
+	if !exists {
+		return m.borderedContentStyle().Render("No test results for " + packageName + "\n\nPress ESC to return")
+	}
+
+	var content, helpText string
+	scroll := m.coverageView.scroll
+	if m.coverageView.source != nil {
+		content, _ = FormatCoverageSource(m.coverageView.source, m.currentTheme, m.coverageView, m.width-6)
+		helpText = fmt.Sprintf("%s | ↑↓/jk: scroll | PgUp/PgDn: page | g/G: top/bottom | n/p: next/prev uncovered block | ESC: file list", m.coverageView.source.file.FileName)
+	} else {
+		content, _ = FormatCoverageFiles(result, m.currentTheme, m.coverageView)
+		scroll = m.coverageView.listScroll
+		helpText = fmt.Sprintf("%s | ↑↓/jk: select file | Enter: view source | ESC: return", packageName)
+	}
+	contentLines := strings.Split(content, "\n")
+	visibleLines := contentHeight - 2 // Account for border padding
+
+	// Extract visible portion of content
+	start := scroll
+	if start > len(contentLines) {
+		start = len(contentLines)
+	}
+	end := start + visibleLines
+	if end > len(contentLines) {
+		end = len(contentLines)
+	}
+	visibleContent := strings.Join(contentLines[start:end], "\n")
+
+	return m.borderedContentStyle().Render(visibleContent) + "\n" + m.helpBarStyle().Render(helpText)
+}
+
 func (m model) renderFullCoverageGaps() string {
 	contentHeight := m.height - MenuBarH
 
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StackFrame is one call in a goroutine stack trace
+type StackFrame struct {
+	Function string // Fully qualified function, e.g. example.com/pkg.TestFoo
+	Args     string // Raw argument list as printed by the runtime
+	File     string
+	Line     int
+}
+
+// Location returns the frame's file:line
+func (f StackFrame) Location() string {
+	if f.File == "" {
+		return ""
+	}
+	return f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// Goroutine is one goroutine of a runtime stack dump
+type Goroutine struct {
+	ID        int
+	State     string       // e.g. "running", "chan receive, 2 minutes"
+	Frames    []StackFrame // Innermost call first
+	CreatedBy *StackFrame  // Where the goroutine was started, if known
+}
+
+// TimeoutPanic is a parsed "panic: test timed out after" report from go test -timeout
+type TimeoutPanic struct {
+	After        string        // Timeout that expired, e.g. "10m0s"
+	RunningTests []RunningTest // Tests still running when the timeout fired
+	Goroutines   []Goroutine
+}
+
+// RunningTest is a test listed as still running by a timeout panic
+type RunningTest struct {
+	Name     string
+	Duration string // How long it had been running, e.g. "2s"
+}
+
+// timeoutPanicPrefix starts the panic go test prints when -timeout expires
+const timeoutPanicPrefix = "panic: test timed out after "
+
+var (
+	// goroutineHeaderRegex matches "goroutine 7 [chan receive, 2 minutes]:"
+	goroutineHeaderRegex = regexp.MustCompile(`^goroutine (\d+) (?:gp=\S+ m=\S+ (?:mp=\S+ )?)?\[(.*)\]:$`)
+	// frameLocationRegex matches "\t/path/file.go:123 +0x1d"
+	frameLocationRegex = regexp.MustCompile(`^\t(.+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	// runningTestRegex matches "\t\tTestHang (2s)" in the running tests list
+	runningTestRegex = regexp.MustCompile(`^\t\t(\S+) \((.+)\)$`)
+)
+
+// parseTimeoutPanic parses a timeout panic and its goroutine dump from test output
+// Returns nil if output has no timeout panic
+func parseTimeoutPanic(output string) *TimeoutPanic {
+	start := strings.Index(output, timeoutPanicPrefix)
+	if start < 0 {
+		return nil
+	}
+	lines := strings.Split(output[start:], "\n")
+
+	timeout := &TimeoutPanic{
+		After: strings.TrimSpace(strings.TrimPrefix(lines[0], timeoutPanicPrefix)),
+	}
+
+	i := 1
+	if i < len(lines) && strings.TrimSpace(lines[i]) == "running tests:" {
+		for i++; i < len(lines); i++ {
+			matches := runningTestRegex.FindStringSubmatch(lines[i])
+			if matches == nil {
+				break
+			}
+			timeout.RunningTests = append(timeout.RunningTests, RunningTest{Name: matches[1], Duration: matches[2]})
+		}
+	}
+
+	timeout.Goroutines = parseGoroutines(lines[i:])
+	return timeout
+}
+
+// parseGoroutines parses the goroutines of a runtime stack dump
+// Lines that are not part of a goroutine block are skipped
+func parseGoroutines(lines []string) []Goroutine {
+	var goroutines []Goroutine
+	var current *Goroutine
+	createdBy := false
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+
+		if matches := goroutineHeaderRegex.FindStringSubmatch(line); matches != nil {
+			if current != nil {
+				goroutines = append(goroutines, *current)
+			}
+			id, _ := strconv.Atoi(matches[1])
+			current = &Goroutine{ID: id, State: matches[2]}
+			createdBy = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			// A blank line ends the goroutine block
+			goroutines = append(goroutines, *current)
+			current = nil
+			continue
+		}
+
+		if matches := frameLocationRegex.FindStringSubmatch(line); matches != nil {
+			lineNum, _ := strconv.Atoi(matches[2])
+			if createdBy && current.CreatedBy != nil {
+				current.CreatedBy.File = matches[1]
+				current.CreatedBy.Line = lineNum
+			} else if len(current.Frames) > 0 {
+				frame := &current.Frames[len(current.Frames)-1]
+				frame.File = matches[1]
+				frame.Line = lineNum
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "created by ") {
+			function := strings.TrimPrefix(line, "created by ")
+			// Drop the " in goroutine N" suffix added by newer runtimes
+			if idx := strings.Index(function, " in goroutine "); idx >= 0 {
+				function = function[:idx]
+			}
+			current.CreatedBy = &StackFrame{Function: function}
+			createdBy = true
+			continue
+		}
+
+		if strings.HasPrefix(line, "...") {
+			// "...additional frames elided..."
+			continue
+		}
+
+		if !strings.HasSuffix(line, ")") {
+			// Not a call - trailing output such as "FAIL\tpkg\t2.004s" ends the block
+			goroutines = append(goroutines, *current)
+			current = nil
+			continue
+		}
+
+		current.Frames = append(current.Frames, parseFrameCall(line))
+	}
+
+	if current != nil {
+		goroutines = append(goroutines, *current)
+	}
+	return goroutines
+}
+
+// parseFrameCall splits a stack frame call line into function and arguments
+// e.g. "example.com/pkg.TestHang(0x2f7a18372488?)"
+func parseFrameCall(line string) StackFrame {
+	line = strings.TrimSpace(line)
+	// Find the parenthesis opening the argument list; function names can contain
+	// parentheses themselves, e.g. testing.(*T).Run
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return StackFrame{Function: line[:i], Args: line[i+1 : len(line)-1]}
+			}
+		}
+	}
+	return StackFrame{Function: line}
+}
+
+// runsTest reports whether a goroutine is executing the given top-level test function
+func (g Goroutine) runsTest(testName string) bool {
+	topLevel := strings.SplitN(testName, "/", 2)[0]
+	for _, frame := range g.Frames {
+		if strings.HasSuffix(frame.Function, "."+topLevel) || strings.Contains(frame.Function, "."+topLevel+".func") {
+			return true
+		}
+	}
+	return false
+}
+
+// PanicReport is a parsed panic of a test and the stack of the goroutine that panicked
+type PanicReport struct {
+	Message   string    // Panic value, e.g. "assignment to entry in nil map"
+	Test      string    // Test that panicked
+	Goroutine Goroutine // Panicking goroutine; Frames are innermost first
+}
+
+// panicPrefix starts the line the runtime prints for an unrecovered panic
+const panicPrefix = "panic: "
+
+// isPanicLine reports whether an output line starts a panic other than a test timeout
+func isPanicLine(line string) bool {
+	return strings.HasPrefix(line, panicPrefix) && !strings.HasPrefix(line, timeoutPanicPrefix)
+}
+
+// panicDumpFollows reports whether the lines after a panic line reach the header
+// of the panicking goroutine before any go test output such as "--- PASS"
+// A test that merely prints a line starting with "panic: " has no dump after it
+func panicDumpFollows(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case goroutineHeaderRegex.MatchString(line):
+			return true
+		case isTestFrameworkLine(line):
+			return false
+		}
+	}
+	return false
+}
+
+// isTestFrameworkLine reports whether a line is printed by go test itself,
+// e.g. "=== RUN", "--- PASS: TestFoo (0.00s)", "PASS" or "ok  	example.com/pkg"
+func isTestFrameworkLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") ||
+		trimmed == "PASS" || trimmed == "FAIL" ||
+		strings.HasPrefix(line, "ok  \t") || strings.HasPrefix(line, "FAIL\t")
+}
+
+// parsePanic parses the first panic in test output and its goroutine's stack
+// Returns nil if output has no panic
+func parsePanic(output string) *PanicReport {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if !isPanicLine(line) || !panicDumpFollows(lines[i+1:]) {
+			continue
+		}
+
+		message := strings.TrimPrefix(line, panicPrefix)
+		// Drop the " [recovered]" / " [recovered, repanicked]" note added by the testing package
+		if idx := strings.LastIndex(message, " [recovered"); idx >= 0 && strings.HasSuffix(message, "]") {
+			message = message[:idx]
+		}
+		report := &PanicReport{Message: message}
+
+		// The first goroutine of the dump is the one that panicked
+		if goroutines := parseGoroutines(lines[i+1:]); len(goroutines) > 0 {
+			report.Goroutine = goroutines[0]
+		}
+		return report
+	}
+	return nil
+}
+
+// funcPackage returns the import path of the package a stack frame's function
+// belongs to, e.g. "example.com/pkg" for "example.com/pkg.(*T).Method"
+func funcPackage(function string) string {
+	lastSlash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[lastSlash+1:], "."); dot >= 0 {
+		return function[:lastSlash+1+dot]
+	}
+	return function
+}
+
+// isRuntimeFrame reports whether a frame belongs to the runtime or testing
+// machinery rather than to code under test
+func isRuntimeFrame(frame StackFrame) bool {
+	pkg := funcPackage(frame.Function)
+	switch {
+	case pkg == "panic", pkg == "runtime", pkg == "testing":
+		return true
+	case strings.HasPrefix(pkg, "runtime/"), strings.HasPrefix(pkg, "testing/"):
+		return true
+	}
+	return false
+}
+
+// isModuleFrame reports whether a frame belongs to the given module
+// With no module path, every frame outside the runtime and testing packages counts
+func isModuleFrame(frame StackFrame, modulePath string) bool {
+	if modulePath == "" {
+		return !isRuntimeFrame(frame)
+	}
+	pkg := funcPackage(frame.Function)
+	return pkg == modulePath || strings.HasPrefix(pkg, modulePath+"/")
+}
+
+// firstModuleFrame returns the index of the innermost frame in the module, or -1
+func firstModuleFrame(frames []StackFrame, modulePath string) int {
+	for i, frame := range frames {
+		if isModuleFrame(frame, modulePath) {
+			return i
+		}
+	}
+	return -1
+}
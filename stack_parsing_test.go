@@ -0,0 +1,230 @@
+package main
+
+import "testing"
+
+// panicOutput is the output of a test that panicked, as go test prints it
+const panicOutput = `=== RUN   TestStore
+--- FAIL: TestStore (0.00s)
+panic: assignment to entry in nil map [recovered]
+	panic: assignment to entry in nil map
+
+goroutine 7 [running]:
+testing.tRunner.func1.2({0x5a1f20, 0x61c8d0})
+	/usr/local/go/src/testing/testing.go:1632 +0x230
+panic({0x5a1f20?, 0x61c8d0?})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+example.com/app/store.(*Store).Put(...)
+	/src/app/store/store.go:42
+example.com/app/store.TestStore(0xc000007d40?)
+	/src/app/store/store_test.go:11 +0x2c
+testing.tRunner(0xc000007d40, 0x5d9a48)
+	/usr/local/go/src/testing/testing.go:1689 +0xfb
+created by testing.(*T).Run in goroutine 1
+	/usr/local/go/src/testing/testing.go:1742 +0x390
+FAIL	example.com/app/store	0.005s
+`
+
+func TestParsePanic(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		wantMessage string // "" for no panic
+		wantFrames  int
+		wantFunc    string // Innermost frame
+		wantFile    string
+		wantLine    int
+	}{
+		{
+			name:        "test panic",
+			output:      panicOutput,
+			wantMessage: "assignment to entry in nil map",
+			wantFrames:  5,
+			wantFunc:    "testing.tRunner.func1.2",
+			wantFile:    "/usr/local/go/src/testing/testing.go",
+			wantLine:    1632,
+		},
+		{
+			name: "package panic without testing frames",
+			output: "panic: boom\n\ngoroutine 1 [running]:\nexample.com/app.init.0()\n\t/src/app/app.go:5 +0x25\n" +
+				"exit status 2\n",
+			wantMessage: "boom",
+			wantFrames:  1,
+			wantFunc:    "example.com/app.init.0",
+			wantFile:    "/src/app/app.go",
+			wantLine:    5,
+		},
+		{
+			name:        "multi-line panic message",
+			output:      "panic: first line\nsecond line\n\ngoroutine 1 [running]:\nexample.com/app.f()\n\t/src/app/app.go:9 +0x25\n",
+			wantMessage: "first line",
+			wantFrames:  1,
+			wantFunc:    "example.com/app.f",
+			wantFile:    "/src/app/app.go",
+			wantLine:    9,
+		},
+		{
+			name:   "passing test printing a panic line",
+			output: "=== RUN   TestLog\npanic: not really\n--- PASS: TestLog (0.00s)\nPASS\nok  \texample.com/app\t0.003s\n",
+		},
+		{
+			name:   "panic line without a stack",
+			output: "panic: not really\n",
+		},
+		{
+			name:   "timeout panic",
+			output: "panic: test timed out after 1s\nrunning tests:\n\t\tTestHang (1s)\n\ngoroutine 1 [running]:\n",
+		},
+		{
+			name:   "no panic",
+			output: "=== RUN   TestOK\n--- PASS: TestOK (0.00s)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := parsePanic(tt.output)
+			if tt.wantMessage == "" {
+				if report != nil {
+					t.Fatalf("parsePanic() = %+v, want nil", report)
+				}
+				return
+			}
+			if report == nil {
+				t.Fatal("parsePanic() = nil, want a report")
+			}
+			if report.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", report.Message, tt.wantMessage)
+			}
+			frames := report.Goroutine.Frames
+			if len(frames) != tt.wantFrames {
+				t.Fatalf("got %d frames, want %d: %+v", len(frames), tt.wantFrames, frames)
+			}
+			if frames[0].Function != tt.wantFunc || frames[0].File != tt.wantFile || frames[0].Line != tt.wantLine {
+				t.Errorf("innermost frame = %s %s:%d, want %s %s:%d",
+					frames[0].Function, frames[0].File, frames[0].Line, tt.wantFunc, tt.wantFile, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestParseTimeoutPanic(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		wantNil        bool
+		wantAfter      string
+		wantRunning    []RunningTest
+		wantGoroutines []Goroutine // Frames are compared by function only
+	}{
+		{
+			name: "running tests and goroutines",
+			output: "=== RUN   TestHang\n" +
+				"panic: test timed out after 2s\n" +
+				"running tests:\n" +
+				"\t\tTestHang (2s)\n" +
+				"\t\tTestWait/slow (1s)\n" +
+				"\n" +
+				"goroutine 21 [running]:\n" +
+				"testing.(*M).startAlarm.func1()\n" +
+				"\t/usr/local/go/src/testing/testing.go:2366 +0x265\n" +
+				"created by time.goFunc\n" +
+				"\t/usr/local/go/src/time/sleep.go:177 +0x2d\n" +
+				"\n" +
+				"goroutine 7 [chan receive, 2 minutes]:\n" +
+				"example.com/app.TestHang(0xc000007d40?)\n" +
+				"\t/src/app/app_test.go:14 +0x3c\n" +
+				"testing.tRunner(0xc000007d40, 0x5d9a48)\n" +
+				"\t/usr/local/go/src/testing/testing.go:1689 +0xfb\n" +
+				"FAIL\texample.com/app\t2.004s\n",
+			wantAfter: "2s",
+			wantRunning: []RunningTest{
+				{Name: "TestHang", Duration: "2s"},
+				{Name: "TestWait/slow", Duration: "1s"},
+			},
+			wantGoroutines: []Goroutine{
+				{ID: 21, State: "running", Frames: []StackFrame{{Function: "testing.(*M).startAlarm.func1"}}},
+				{ID: 7, State: "chan receive, 2 minutes", Frames: []StackFrame{
+					{Function: "example.com/app.TestHang"},
+					{Function: "testing.tRunner"},
+				}},
+			},
+		},
+		{
+			name:      "newer runtime goroutine header",
+			output:    "panic: test timed out after 10m0s\n\ngoroutine 5 gp=0xc000003340 m=nil [select]:\nexample.com/app.wait()\n\t/src/app/app.go:3 +0x1\n",
+			wantAfter: "10m0s",
+			wantGoroutines: []Goroutine{
+				{ID: 5, State: "select", Frames: []StackFrame{{Function: "example.com/app.wait"}}},
+			},
+		},
+		{
+			name:    "regular panic",
+			output:  panicOutput,
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout := parseTimeoutPanic(tt.output)
+			if tt.wantNil {
+				if timeout != nil {
+					t.Fatalf("parseTimeoutPanic() = %+v, want nil", timeout)
+				}
+				return
+			}
+			if timeout == nil {
+				t.Fatal("parseTimeoutPanic() = nil, want a report")
+			}
+			if timeout.After != tt.wantAfter {
+				t.Errorf("After = %q, want %q", timeout.After, tt.wantAfter)
+			}
+			if len(timeout.RunningTests) != len(tt.wantRunning) {
+				t.Fatalf("RunningTests = %+v, want %+v", timeout.RunningTests, tt.wantRunning)
+			}
+			for i, running := range tt.wantRunning {
+				if timeout.RunningTests[i] != running {
+					t.Errorf("RunningTests[%d] = %+v, want %+v", i, timeout.RunningTests[i], running)
+				}
+			}
+			if len(timeout.Goroutines) != len(tt.wantGoroutines) {
+				t.Fatalf("got %d goroutines, want %d: %+v", len(timeout.Goroutines), len(tt.wantGoroutines), timeout.Goroutines)
+			}
+			for i, want := range tt.wantGoroutines {
+				got := timeout.Goroutines[i]
+				if got.ID != want.ID || got.State != want.State || len(got.Frames) != len(want.Frames) {
+					t.Errorf("goroutine %d = %d [%s] with %d frames, want %d [%s] with %d frames",
+						i, got.ID, got.State, len(got.Frames), want.ID, want.State, len(want.Frames))
+					continue
+				}
+				for j, frame := range want.Frames {
+					if got.Frames[j].Function != frame.Function {
+						t.Errorf("goroutine %d frame %d = %s, want %s", i, j, got.Frames[j].Function, frame.Function)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEventParserIgnoresPrintedPanicLine(t *testing.T) {
+	result := newPackageTestResult("app")
+	parser := newTestEventParser(result)
+	for _, event := range []TestEvent{
+		{Action: "run", Test: "TestLog"},
+		{Action: "output", Test: "TestLog", Output: "=== RUN   TestLog\n"},
+		{Action: "output", Test: "TestLog", Output: "panic: not really\n"},
+		{Action: "output", Test: "TestLog", Output: "--- PASS: TestLog (0.00s)\n"},
+		{Action: "pass", Test: "TestLog"},
+		{Action: "output", Output: "PASS\n"},
+		{Action: "pass"},
+	} {
+		parser.Consume(event)
+	}
+	if result.Panic != nil {
+		t.Errorf("result.Panic = %+v, want nil", result.Panic)
+	}
+	if len(result.Tests) != 1 || result.Tests[0].Panic != nil {
+		t.Errorf("Tests = %+v, want TestLog without a panic", result.Tests)
+	}
+}
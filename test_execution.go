@@ -1,77 +1,243 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// cancelWaitDelay bounds how long Wait blocks on output pipes after a cancelled
+// go test process has been killed
+const cancelWaitDelay = 5 * time.Second
+
+// runningTestProcesses tracks started go test processes so they can be reaped on exit
+var runningTestProcesses sync.WaitGroup
+
+// testRunOptions adjusts a single go test invocation
+type testRunOptions struct {
+	runPattern string      // -run pattern; empty runs every test
+	profile    FlagProfile // Per-directory race/timeout/tags/env/extra flags
+	allTags    []string    // Tags built by "all" mode: every tag the package's test files use
+	testFuncs  []TestFunc  // Test functions found by the scanner, used to classify tests statically
+	count      int         // Times each test runs (-count); 0 runs once
+	shuffle    string      // -shuffle seed to replay a previous order; empty uses the profile's setting
+	profileDir string      // Directory -cpuprofile and -memprofile write to; empty runs without profiling
+	coverPkg   string      // -coverpkg patterns measured besides the package; empty measures only the package
+}
+
+// isPartial reports whether the run only covers some of the package's tests
+// Partial results are merged into the previous result instead of replacing it
+func (o testRunOptions) isPartial() bool {
+	return o.runPattern != ""
+}
+
+// countArg returns the -count flag for the options
+// Tests always run at least once and are never served from the test cache
+func (o testRunOptions) countArg() string {
+	if o.count > 1 {
+		return "-count=" + strconv.Itoa(o.count)
+	}
+	return "-count=1"
+}
+
+// coverModeArg returns the -covermode flag for the options
+// Blocks are counted so the source view can show hit counts; -race needs atomic counters
+func (o testRunOptions) coverModeArg() string {
+	if o.profile.Race {
+		return "-covermode=atomic"
+	}
+	return "-covermode=count"
+}
+
+// args returns the go test flags for the options
+// Build tags are not included; see testModeArgs
+func (o testRunOptions) args() []string {
+	var args []string
+	if o.runPattern != "" {
+		args = append(args, "-run="+o.runPattern)
+	}
+	if o.profile.Race {
+		args = append(args, "-race")
+	}
+	if o.shuffle != "" {
+		args = append(args, "-shuffle="+o.shuffle)
+	} else if o.profile.Shuffle {
+		args = append(args, "-shuffle=on")
+	}
+	if o.profile.Timeout != "" {
+		args = append(args, "-timeout="+o.profile.Timeout)
+	}
+	if o.coverPkg != "" {
+		args = append(args, "-coverpkg="+o.coverPkg)
+	}
+	return append(args, o.profile.ExtraArgs...)
+}
+
 // RunTests executes tests for a specific package
 // packageDir is the directory containing the test files
-// mode specifies which tests to run (unit, integration, or all)
-func RunTests(packageDir string, packageName string, mode testMode) (*PackageTestResult, error) {
+// mode specifies which tests to run (unit, a discovered tag set, or all)
+// opts adjusts the invocation, e.g. to run only some tests
+// onEvent, if non-nil, is called for every test event as it is read from go test
+// Cancelling ctx kills the go test process group; the partial result is returned
+// with status "CANCELLED"
+func RunTests(ctx context.Context, packageDir string, packageName string, mode testMode, opts testRunOptions, onEvent func(TestEvent)) (*PackageTestResult, error) {
 	LogInfo("Running tests",
 		"package", packageName,
 		"directory", packageDir,
 		"mode", mode,
+		"run", opts.runPattern,
+		"profile", opts.profile.String(),
+		"shuffle", opts.shuffle,
+		"coverpkg", opts.coverPkg,
 	)
 
-	// For "All" mode, run tests twice and compare to identify integration tests
-	if mode == testModeAll {
-		return runAllTests(packageDir, packageName)
+	// For "All" mode, run tests twice and compare to identify tagged tests
+	if mode == testModeAll && len(opts.allTags) > 0 {
+		return runAllTests(ctx, packageDir, packageName, opts, onEvent)
 	}
 
-	// Single run for unit or integration mode
-	return runSingleTestMode(packageDir, packageName, mode)
+	// Single run for unit or tagged mode
+	return runSingleTestMode(ctx, packageDir, packageName, mode, opts, onEvent)
 }
 
-// runSingleTestMode runs tests once with the specified mode
-func runSingleTestMode(packageDir string, packageName string, mode testMode) (*PackageTestResult, error) {
-	result := &PackageTestResult{
+// newPackageTestResult creates an empty result for a package about to be tested
+func newPackageTestResult(packageName string) *PackageTestResult {
+	return &PackageTestResult{
 		PackagePath:       packageName,
 		Status:            "RUNNING",
 		Tests:             []TestResult{},
 		FileCoverages:     []FileCoverage{},
 		FunctionCoverages: []FunctionCoverage{},
 	}
+}
 
-	// Create temp file for coverage output
+// createCoverageFile creates a temp file for a coverage profile
+// Every go test invocation gets its own file so concurrent runs stay isolated
+func createCoverageFile() (string, error) {
 	tempFile, err := os.CreateTemp("", "gapistotle-coverage-*.out")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp coverage file: %w", err)
+		return "", fmt.Errorf("failed to create temp coverage file: %w", err)
 	}
-	coverageFile := tempFile.Name()
 	tempFile.Close()
-	defer os.Remove(coverageFile) // Clean up after parsing
-
-	// Build command args based on test mode
-	args := []string{"test", "-json", "-cover", "-coverprofile=" + coverageFile, "-count=1"}
+	return tempFile.Name(), nil
+}
 
-	// Add tags based on mode
+// testModeArgs returns the extra go test flags for a mode and the test type
+// assigned to tests found by that run ("unit", or the mode's tags joined by commas)
+// extraTags (e.g. from a flag profile) are combined with the mode's tags into one -tags flag
+func testModeArgs(mode testMode, extraTags []string) ([]string, string) {
 	testType := "unit"
-	if mode == testModeIntegration {
-		args = append(args, "-tags=integration")
-		testType = "integration"
+	if modeTags := mode.Tags(); len(modeTags) > 0 {
+		testType = strings.Join(modeTags, ",")
 	}
+	tags := runBuildTags(mode, extraTags)
+	if len(tags) == 0 {
+		return nil, testType
+	}
+	return []string{"-tags=" + strings.Join(tags, ",")}, testType
+}
 
-	args = append(args, ".")
-
-	// Run go test in the directory containing the tests
-	cmd := exec.Command("go", args...)
-	cmd.Dir = packageDir
+// runBuildTags returns every custom tag a run of mode is built with
+func runBuildTags(mode testMode, extraTags []string) []string {
+	return append(mode.Tags(), extraTags...)
+}
 
-	// Capture combined output
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
-	result.FullOutput = outputStr
+// staticTestTypes maps top-level test names to their test type for a run built
+// with tags, from the files the scanner found each function in
+// Only functions whose file is compiled with tags are included, so a test
+// declared in both a !integration and an integration file gets the right type
+func staticTestTypes(funcs []TestFunc, tags []string) map[string]string {
+	types := make(map[string]string, len(funcs))
+	for _, fn := range funcs {
+		if buildsWithTags(fn.constraint, tags) {
+			types[fn.Name] = fn.TestType()
+		}
+	}
+	return types
+}
 
-	// Parse the output and tag tests with type
-	parseTestOutput(result, outputStr)
+// applyTestTypes sets the test type of every test from the type of its top-level
+// test function; tests the scanner didn't find get fallback
+func applyTestTypes(result *PackageTestResult, types map[string]string, fallback string) {
 	for i := range result.Tests {
-		result.Tests[i].TestType = testType
+		topLevel := strings.SplitN(result.Tests[i].Name, "/", 2)[0]
+		if testType, exists := types[topLevel]; exists {
+			result.Tests[i].TestType = testType
+		} else {
+			result.Tests[i].TestType = fallback
+		}
 	}
+}
 
-	// Parse coverage profile if it exists
+// untaggedOnlyTests returns the test functions that are compiled without custom
+// tags but not with tags, e.g. functions in //go:build !integration files
+func untaggedOnlyTests(funcs []TestFunc, tags []string) []string {
+	var names []string
+	for _, fn := range funcs {
+		if buildsWithTags(fn.constraint, nil) && !buildsWithTags(fn.constraint, tags) {
+			names = append(names, fn.Name)
+		}
+	}
+	return names
+}
+
+// startGoTest starts go test in dir with stdout available for streaming
+// env holds KEY=VALUE pairs added to the inherited environment
+// The process runs in its own process group so cancelling ctx kills test binaries too
+func startGoTest(ctx context.Context, dir string, args []string, env []string) (*exec.Cmd, io.Reader, *bytes.Buffer, error) {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	configureProcessGroup(cmd)
+	cmd.WaitDelay = cancelWaitDelay
+
+	// Stream stdout; stderr is collected separately and appended to the full output
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to capture go test output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to start go test: %w", err)
+	}
+	runningTestProcesses.Add(1)
+	return cmd, stdout, stderr, nil
+}
+
+// waitGoTest waits for a process started by startGoTest to exit
+func waitGoTest(cmd *exec.Cmd) error {
+	defer runningTestProcesses.Done()
+	return cmd.Wait()
+}
+
+// readTestOutput reads go test output line by line as it is produced
+// handleLine receives each line including its trailing newline
+func readTestOutput(r io.Reader, handleLine func(line string)) {
+	reader := bufio.NewReader(r)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if line != "" {
+			handleLine(line)
+		}
+		if readErr != nil {
+			return
+		}
+	}
+}
+
+// applyCoverageProfile parses a coverage profile into result if the file exists
+func applyCoverageProfile(result *PackageTestResult, coverageFile string, packageDir string) {
 	if _, statErr := os.Stat(coverageFile); statErr == nil {
 		parseCoverageProfile(result, coverageFile)
 		parseFunctionCoverage(result, coverageFile, packageDir)
@@ -85,62 +251,154 @@ func runSingleTestMode(packageDir string, packageName string, mode testMode) (*P
 			"error", statErr,
 		)
 	}
+}
 
-	// Determine overall status
-	if err != nil {
-		result.Status = "FAIL"
-	} else {
-		result.Status = "PASS"
-	}
-
+// logTestCompletion logs the outcome of a package test run
+func logTestCompletion(result *PackageTestResult) {
 	LogInfo("Test execution complete",
-		"package", packageName,
+		"package", result.PackagePath,
 		"status", result.Status,
 		"coverage", result.Coverage,
 		"total_tests", result.TotalTests,
 		"passed", result.PassedTests,
 		"failed", result.FailedTests,
+		"failure_kind", string(result.FailureKind),
 		"duration", result.Duration.String(),
 	)
+}
+
+// runSingleTestMode runs tests once with the specified mode
+// Output is read incrementally so each event is parsed (and forwarded to onEvent) as it arrives
+func runSingleTestMode(ctx context.Context, packageDir string, packageName string, mode testMode, opts testRunOptions, onEvent func(TestEvent)) (*PackageTestResult, error) {
+	result := newPackageTestResult(packageName)
+
+	// Create temp file for coverage output
+	coverageFile, err := createCoverageFile()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(coverageFile) // Clean up after parsing
+
+	// Build command args based on test mode
+	args := []string{"test", "-json", "-cover", "-coverprofile=" + coverageFile, opts.coverModeArg(), opts.countArg()}
+	modeArgs, testType := testModeArgs(mode, opts.profile.Tags)
+	args = append(args, modeArgs...)
+	args = append(args, opts.args()...)
+	if opts.profileDir != "" {
+		profileArgs, err := prepareProfileDir(opts.profileDir)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, profileArgs...)
+	}
+	args = append(args, ".")
+
+	// Run go test in the directory containing the tests
+	cmd, stdout, stderr, err := startGoTest(ctx, packageDir, args, opts.profile.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse events as they arrive
+	parser := newTestEventParser(result)
+	var output strings.Builder
+	readTestOutput(stdout, func(line string) {
+		output.WriteString(line)
+		if event, ok := decodeTestEvent(line); ok {
+			parser.Consume(event)
+			if onEvent != nil {
+				onEvent(event)
+			}
+		}
+	})
+
+	err = waitGoTest(cmd)
+	parser.exposeRunningOutput()
+	output.WriteString(stderr.String())
+	result.FullOutput = output.String()
+
+	// With -count above 1 every test reports once per run
+	if opts.count > 1 {
+		aggregateRepeatedRuns(result, opts.count)
+	}
+
+	// Tag tests with type, from the file each test is declared in when known
+	types := staticTestTypes(opts.testFuncs, runBuildTags(mode, opts.profile.Tags))
+	applyTestTypes(result, types, testType)
+
+	// Parse coverage profile if it exists
+	if opts.coverPkg != "" {
+		result.CoverPkg = opts.coverPkg
+		applyCrossPackageCoverage(ctx, result, coverageFile, packageDir)
+	} else {
+		applyCoverageProfile(result, coverageFile, packageDir)
+	}
+	if opts.profileDir != "" {
+		applyProfiles(result, opts.profileDir)
+	}
+
+	// Compiler errors are reported relative to the package directory
+	resolveBuildDiagnostics(result.BuildErrors, packageDir)
+
+	// Determine overall status
+	if ctx.Err() != nil {
+		result.Status = "CANCELLED"
+	} else if parser.failedBuild != "" {
+		result.Status = buildFailStatus
+	} else if err != nil {
+		result.Status = "FAIL"
+	} else {
+		result.Status = "PASS"
+	}
+	applyFlakyStatus(result)
+	parser.classifyFailure(err)
+
+	logTestCompletion(result)
 
 	return result, nil
 }
 
-// runAllTests runs both unit and integration tests and combines results
-func runAllTests(packageDir string, packageName string) (*PackageTestResult, error) {
-	// Run unit tests first (no tags)
-	unitResult, unitErr := runSingleTestMode(packageDir, packageName, testModeUnit)
-	if unitErr != nil {
-		return nil, unitErr
+// runAllTests runs every test of the package in a single run built with all of
+// its tags; test types come from the files tests are declared in
+// Tests that only build without tags (e.g. in //go:build !integration files) get
+// a second, untagged run limited to them; its outcomes are merged in but the
+// coverage is that of the tagged run
+func runAllTests(ctx context.Context, packageDir string, packageName string, opts testRunOptions, onEvent func(TestEvent)) (*PackageTestResult, error) {
+	allMode := tagsMode(opts.allTags)
+	result, err := runSingleTestMode(ctx, packageDir, packageName, allMode, opts, onEvent)
+	if err != nil {
+		return nil, err
 	}
-
-	// Build map of unit test names for comparison
-	unitTestNames := make(map[string]bool)
-	for _, test := range unitResult.Tests {
-		unitTestNames[test.Name] = true
+	if result.Status == "CANCELLED" {
+		return result, nil
 	}
 
-	// Run with integration tags to get all tests
-	allResult, allErr := runSingleTestMode(packageDir, packageName, testModeIntegration)
-	if allErr != nil {
-		return nil, allErr
+	untaggedOnly := untaggedOnlyTests(opts.testFuncs, runBuildTags(allMode, opts.profile.Tags))
+	if len(untaggedOnly) == 0 {
+		return result, nil
 	}
 
-	// Tag tests: if it's in unitTestNames, it's a unit test; otherwise it's integration
-	for i := range allResult.Tests {
-		if unitTestNames[allResult.Tests[i].Name] {
-			allResult.Tests[i].TestType = "unit"
-		} else {
-			allResult.Tests[i].TestType = "integration"
+	untaggedOpts := opts
+	untaggedOpts.profileDir = "" // The tagged run's profiles are kept, like its coverage
+	untaggedOpts.runPattern = buildRunPattern(untaggedOnly)
+	if opts.isPartial() {
+		// Only the selected tests the tagged run couldn't build
+		untaggedOpts.runPattern = restrictRunPattern(opts.runPattern, untaggedOnly)
+		if untaggedOpts.runPattern == "" {
+			return result, nil
 		}
 	}
+	untaggedResult, err := runSingleTestMode(ctx, packageDir, packageName, testModeUnit, untaggedOpts, onEvent)
+	if err != nil {
+		return nil, err
+	}
 
-	LogInfo("All tests execution complete (unit + integration)",
+	merged := mergeTestResults(result, untaggedResult)
+	LogInfo("All tests execution complete (tagged + untagged-only)",
 		"package", packageName,
-		"total_tests", allResult.TotalTests,
-		"unit_tests", len(unitTestNames),
-		"integration_tests", allResult.TotalTests-len(unitTestNames),
+		"tags", strings.Join(opts.allTags, ","),
+		"untagged_only", len(untaggedOnly),
+		"total_tests", merged.TotalTests,
 	)
-
-	return allResult, nil
+	return merged, nil
 }
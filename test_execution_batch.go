@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// executionStrategy controls how Know It All invokes go test
+type executionStrategy string
+
+const (
+	// strategyPerPackage runs one go test process per package directory
+	strategyPerPackage executionStrategy = "per-package"
+	// strategySingle runs one go test process for all packages and splits its output
+	strategySingle executionStrategy = "single"
+)
+
+// batchGroup is a set of packages run together by one RunTestsBatch call
+// Packages only share an invocation when their test mode and flag profile match
+type batchGroup struct {
+	mode     testMode
+	profile  FlagProfile
+	packages []TestPackage
+}
+
+// batchPackage is a package taking part in a single go test invocation
+type batchPackage struct {
+	pkg        TestPackage
+	importPath string
+	dir        string // Absolute package directory
+	result     *PackageTestResult
+	parser     *testEventParser
+	output     strings.Builder
+}
+
+// RunTestsBatch runs the tests of several packages with one go test invocation
+// from rootDir, instead of one invocation per directory
+// Events are demultiplexed by their Package field into one result per package and
+// the merged coverage profile is split by file path back into each package
+// opts apply to every package, so packages with different flag profiles need separate batches
+// onEvent, if non-nil, is called for every event with the name of its package
+// Returns results keyed by package name
+func RunTestsBatch(ctx context.Context, rootDir string, packages []TestPackage, mode testMode, opts testRunOptions, onEvent func(packageName string, event TestEvent)) (map[string]*PackageTestResult, error) {
+	LogInfo("Running tests in a single invocation",
+		"root_dir", rootDir,
+		"package_count", len(packages),
+		"mode", mode,
+		"profile", opts.profile.String(),
+	)
+
+	// For "All" mode, run once with every tag used by any package of the batch
+	allTags := allModeTags(packages...)
+	if mode == testModeAll && len(allTags) > 0 {
+		allMode := tagsMode(allTags)
+		results, err := runBatchTestMode(ctx, rootDir, packages, allMode, opts, onEvent)
+		if err != nil || ctx.Err() != nil {
+			return results, err
+		}
+
+		// Tests that only build without tags get a second, untagged run limited to them
+		runTags := runBuildTags(allMode, opts.profile.Tags)
+		var untaggedPackages []TestPackage
+		var untaggedOnly []string
+		for _, pkg := range packages {
+			if names := untaggedOnlyTests(pkg.TestFuncs, runTags); len(names) > 0 {
+				untaggedPackages = append(untaggedPackages, pkg)
+				untaggedOnly = append(untaggedOnly, names...)
+			}
+		}
+		if len(untaggedOnly) == 0 {
+			return results, nil
+		}
+
+		untaggedOpts := opts
+		untaggedOpts.runPattern = buildRunPattern(untaggedOnly)
+		if opts.isPartial() {
+			// Only the selected tests the tagged run couldn't build
+			untaggedOpts.runPattern = restrictRunPattern(opts.runPattern, untaggedOnly)
+			if untaggedOpts.runPattern == "" {
+				return results, nil
+			}
+		}
+		untaggedResults, err := runBatchTestMode(ctx, rootDir, untaggedPackages, testModeUnit, untaggedOpts, onEvent)
+		if err != nil {
+			return nil, err
+		}
+		for name, untaggedResult := range untaggedResults {
+			if result, exists := results[name]; exists {
+				results[name] = mergeTestResults(result, untaggedResult)
+			}
+		}
+		return results, nil
+	}
+
+	return runBatchTestMode(ctx, rootDir, packages, mode, opts, onEvent)
+}
+
+// runBatchTestMode runs one go test invocation covering every package with the given mode
+func runBatchTestMode(ctx context.Context, rootDir string, packages []TestPackage, mode testMode, opts testRunOptions, onEvent func(packageName string, event TestEvent)) (map[string]*PackageTestResult, error) {
+	if ctx.Err() != nil {
+		return cancelledBatchResults(packages), nil
+	}
+
+	// Resolve import paths - test events and coverage profiles identify packages by them
+	batch, err := resolveBatchPackages(ctx, rootDir, packages)
+	if err != nil {
+		if ctx.Err() != nil {
+			// go list was killed by the cancellation, not a broken package
+			return cancelledBatchResults(packages), nil
+		}
+		return nil, err
+	}
+
+	// Create temp file for the merged coverage output
+	coverageFile, err := createCoverageFile()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(coverageFile) // Clean up after splitting
+
+	args := []string{"test", "-json", "-cover", "-coverprofile=" + coverageFile, opts.coverModeArg(), opts.countArg()}
+	modeArgs, testType := testModeArgs(mode, opts.profile.Tags)
+	args = append(args, modeArgs...)
+	args = append(args, opts.args()...)
+	for _, bp := range batch {
+		args = append(args, bp.dir)
+	}
+
+	cmd, stdout, stderr, err := startGoTest(ctx, rootDir, args, opts.profile.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Demultiplex events by package as they arrive
+	readTestOutput(stdout, func(line string) {
+		event, ok := decodeTestEvent(line)
+		if !ok {
+			return
+		}
+		if event.Package == "" && event.ImportPath != "" {
+			// Build events name the package being built, which may be a dependency
+			// of several tested packages
+			for _, bp := range buildEventPackages(batch, event.ImportPath) {
+				bp.output.WriteString(line)
+				bp.parser.Consume(event)
+				if onEvent != nil {
+					onEvent(bp.pkg.Name, event)
+				}
+			}
+			return
+		}
+		bp, exists := batch[event.Package]
+		if !exists {
+			return
+		}
+		bp.output.WriteString(line)
+		bp.parser.Consume(event)
+		if onEvent != nil {
+			onEvent(bp.pkg.Name, event)
+		}
+	})
+
+	err = waitGoTest(cmd)
+
+	// Split the merged coverage profile back into per-package profiles
+	profiles := splitCoverageProfile(coverageFile)
+
+	results := make(map[string]*PackageTestResult, len(batch))
+	for importPath, bp := range batch {
+		result := bp.result
+		bp.parser.exposeRunningOutput()
+		if opts.count > 1 {
+			aggregateRepeatedRuns(result, opts.count)
+		}
+		types := staticTestTypes(bp.pkg.TestFuncs, runBuildTags(mode, opts.profile.Tags))
+		applyTestTypes(result, types, testType)
+
+		// Package status comes from its own pass/fail event; the exit status only
+		// says whether any package failed
+		resolveBuildDiagnostics(result.BuildErrors, rootDir)
+		switch {
+		case ctx.Err() != nil:
+			result.Status = "CANCELLED"
+		case bp.parser.failedBuild != "":
+			result.Status = buildFailStatus
+		case bp.parser.outcome == "fail":
+			result.Status = "FAIL"
+		case bp.parser.outcome == "pass" || bp.parser.outcome == "skip":
+			result.Status = "PASS"
+		case err != nil:
+			result.Status = "FAIL"
+		default:
+			result.Status = "PASS"
+		}
+		applyFlakyStatus(result)
+		bp.parser.classifyFailure(err)
+
+		// Build errors aren't attributed to a package, so failed packages get stderr
+		if result.Status == "FAIL" || result.Status == buildFailStatus {
+			bp.output.WriteString(stderr.String())
+		}
+		result.FullOutput = bp.output.String()
+
+		if profile, exists := profiles[importPath]; exists {
+			applyBatchCoverage(result, profile, bp.dir)
+		}
+
+		logTestCompletion(result)
+		results[bp.pkg.Name] = result
+	}
+
+	return results, nil
+}
+
+// cancelledBatchResults returns a "CANCELLED" result for every package of a
+// batch cancelled before its go test started
+func cancelledBatchResults(packages []TestPackage) map[string]*PackageTestResult {
+	results := make(map[string]*PackageTestResult, len(packages))
+	for _, pkg := range packages {
+		result := newPackageTestResult(pkg.Name)
+		result.Status = "CANCELLED"
+		results[pkg.Name] = result
+	}
+	return results
+}
+
+// buildEventPackages returns the batch packages a build event belongs to
+// importPath is e.g. "example.com/pkg [example.com/pkg.test]"; events for a
+// package outside the batch (a dependency) go to every package
+func buildEventPackages(batch map[string]*batchPackage, importPath string) []*batchPackage {
+	fields := strings.Fields(importPath)
+	if len(fields) == 0 {
+		return nil
+	}
+	built := strings.TrimSuffix(fields[0], "_test")
+	if bp, exists := batch[built]; exists {
+		return []*batchPackage{bp}
+	}
+	packages := make([]*batchPackage, 0, len(batch))
+	for _, bp := range batch {
+		packages = append(packages, bp)
+	}
+	return packages
+}
+
+// resolveBatchPackages maps the import path of every package to its batch entry
+// using go list, since scanned packages only know their directories
+func resolveBatchPackages(ctx context.Context, rootDir string, packages []TestPackage) (map[string]*batchPackage, error) {
+	byDir := make(map[string]TestPackage, len(packages))
+	args := []string{"list", "-e", "-f", "{{.ImportPath}}\t{{.Dir}}"}
+	for _, pkg := range packages {
+		dir, err := filepath.Abs(pkg.Path)
+		if err != nil {
+			dir = pkg.Path
+		}
+		byDir[dir] = pkg
+		args = append(args, dir)
+	}
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = rootDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve package import paths: %w", err)
+	}
+
+	batch := make(map[string]*batchPackage, len(packages))
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		pkg, exists := byDir[parts[1]]
+		if !exists {
+			continue
+		}
+		result := newPackageTestResult(pkg.Name)
+		batch[parts[0]] = &batchPackage{
+			pkg:        pkg,
+			importPath: parts[0],
+			dir:        parts[1],
+			result:     result,
+			parser:     newTestEventParser(result),
+		}
+	}
+
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("go list found none of the %d packages", len(packages))
+	}
+	return batch, nil
+}
+
+// splitCoverageProfile splits a merged coverage profile by file path
+// Returns import path -> profile contents (each with its own mode line)
+func splitCoverageProfile(profilePath string) map[string]string {
+	file, err := os.Open(profilePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	modeLine := ""
+	profiles := make(map[string]*strings.Builder)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			modeLine = line
+			continue
+		}
+
+		// Format: importpath/file.go:startline.startcol,endline.endcol numstatements covered
+		colon := strings.LastIndex(line, ".go:")
+		if colon < 0 {
+			continue
+		}
+		importPath := path.Dir(line[:colon+3])
+		builder, exists := profiles[importPath]
+		if !exists {
+			builder = &strings.Builder{}
+			profiles[importPath] = builder
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	split := make(map[string]string, len(profiles))
+	for importPath, builder := range profiles {
+		split[importPath] = modeLine + "\n" + builder.String()
+	}
+	return split
+}
+
+// applyBatchCoverage writes a package's share of a split profile to a temp file
+// and parses it like a per-package coverage profile
+func applyBatchCoverage(result *PackageTestResult, profile string, packageDir string) {
+	coverageFile, err := createCoverageFile()
+	if err != nil {
+		LogWarn("Failed to split coverage profile", "package", result.PackagePath, "error", err)
+		return
+	}
+	defer os.Remove(coverageFile)
+
+	if err := os.WriteFile(coverageFile, []byte(profile), 0644); err != nil {
+		LogWarn("Failed to split coverage profile", "package", result.PackagePath, "error", err)
+		return
+	}
+	applyCoverageProfile(result, coverageFile, packageDir)
+}
@@ -9,14 +9,69 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// renderTestGroup renders a group of tests (unit or integration)
-func renderTestGroup(tests []TestResult, output *strings.Builder, passStyle, failStyle, normalStyle, metricStyle lipgloss.Style) {
-	// Group by status: FAIL, PASS, SKIP
-	var failed, passed, skipped []TestResult
+// testCursorMarker prefixes the test row selected in the details view
+const testCursorMarker = "▶ "
+
+// flakyStyle colors tests and packages with mixed outcomes across repeated runs
+var flakyStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff66ff"))
+
+// failureKindStyle colors the kind of failure shown with a failed status
+var failureKindStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff8800"))
+
+// testGroup is the tests of one test type, shown as one section of the details view
+type testGroup struct {
+	testType string
+	tests    []TestResult
+}
+
+// groupTestsByType separates tests by test type
+// Unit tests come first, followed by tagged test types in name order, and
+// examples of every test type last
+func groupTestsByType(tests []TestResult) []testGroup {
+	byType := make(map[string][]TestResult)
+	for _, test := range tests {
+		testType := test.TestType
+		if testType == "" {
+			testType = "unit"
+		}
+		if isExampleTest(test.Name) {
+			testType = exampleTestGroup
+		}
+		byType[testType] = append(byType[testType], test)
+	}
+
+	var types []string
+	for testType := range byType {
+		if testType != "unit" && testType != exampleTestGroup {
+			types = append(types, testType)
+		}
+	}
+	sort.Strings(types)
+	if _, exists := byType["unit"]; exists {
+		types = append([]string{"unit"}, types...)
+	}
+	if _, exists := byType[exampleTestGroup]; exists {
+		types = append(types, exampleTestGroup)
+	}
+
+	groups := make([]testGroup, 0, len(types))
+	for _, testType := range types {
+		groups = append(groups, testGroup{testType: testType, tests: byType[testType]})
+	}
+	return groups
+}
+
+// orderTestGroup orders a group of tests the way it is displayed:
+// failures, then flaky tests, then passes (slowest first), then skipped tests
+func orderTestGroup(tests []TestResult) []TestResult {
+	// Group by status: FAIL, FLAKY, PASS, SKIP
+	var failed, flaky, passed, skipped []TestResult
 	for _, test := range tests {
 		switch test.Status {
 		case "FAIL":
 			failed = append(failed, test)
+		case "FLAKY":
+			flaky = append(flaky, test)
 		case "PASS":
 			passed = append(passed, test)
 		case "SKIP":
@@ -25,27 +80,314 @@ func renderTestGroup(tests []TestResult, output *strings.Builder, passStyle, fai
 	}
 
 	// Sort passed tests by duration (slowest first)
-	sort.Slice(passed, func(i, j int) bool {
+	sort.SliceStable(passed, func(i, j int) bool {
 		return passed[i].Duration > passed[j].Duration
 	})
 
-	// Show failures (just names, details shown above)
-	for _, test := range failed {
-		output.WriteString(failStyle.Render("  [FAIL] ") +
-			normalStyle.Render(fmt.Sprintf("%-45s", test.Name)) +
-			metricStyle.Render(fmt.Sprintf(" %8s", formatDuration(test.Duration))) + "\n")
+	ordered := append(failed, flaky...)
+	ordered = append(ordered, passed...)
+	return append(ordered, skipped...)
+}
+
+// selectableTests returns the tests of a result in the order the details view
+// lists them, for moving the test cursor
+// Subtests of collapsed parents are left out
+func selectableTests(result *PackageTestResult, expanded map[string]bool) []TestResult {
+	var tests []TestResult
+	for _, group := range groupTestsByType(result.Tests) {
+		for _, node := range visibleTestNodes(buildTestTree(group.tests), expanded) {
+			tests = append(tests, node.test)
+		}
+	}
+	return tests
+}
+
+// renderTestGroup renders a group of tests of one test type as a subtest tree
+// Subtests are indented under their parent, which shows how its subtests did and
+// whether they are expanded (▾) or collapsed (▸)
+// The row of selectedTest is marked with the cursor and followed by its output
+func renderTestGroup(tests []TestResult, output *strings.Builder, passStyle, failStyle, normalStyle, metricStyle lipgloss.Style, selectedTest string, expanded map[string]bool) {
+	for _, node := range visibleTestNodes(buildTestTree(tests), expanded) {
+		test := node.test
+		prefix := "  "
+		if test.Name == selectedTest {
+			prefix = testCursorMarker
+		}
+
+		toggle := "  "
+		if len(node.children) > 0 {
+			toggle = "▸ "
+			if isTestExpanded(node, expanded) {
+				toggle = "▾ "
+			}
+		}
+		name := fmt.Sprintf("%-45s", strings.Repeat("  ", node.depth)+toggle+node.label)
+
+		switch test.Status {
+		case "FAIL":
+			// Just names, details shown above
+			output.WriteString(failStyle.Render(prefix+"[FAIL] ") +
+				normalStyle.Render(name) +
+				metricStyle.Render(fmt.Sprintf(" %8s", formatDuration(test.Duration))))
+		case "FLAKY":
+			output.WriteString(flakyStyle.Render(prefix+"[FLKY] ") +
+				normalStyle.Render(name) +
+				metricStyle.Render(fmt.Sprintf(" %8s  %d/%d failed", formatDuration(test.Duration), test.Failures, test.Runs)))
+		case "PASS":
+			output.WriteString(passStyle.Render(prefix+"[PASS] ") +
+				normalStyle.Render(name) +
+				metricStyle.Render(fmt.Sprintf(" %8s", formatDuration(test.Duration))))
+		case "SKIP":
+			output.WriteString(normalStyle.Render(fmt.Sprintf("%s[SKIP] %s (skipped)", prefix, name)))
+		default:
+			// A parent that never finished, listed for its subtests
+			output.WriteString(metricStyle.Render(prefix+"[----] ") + normalStyle.Render(name))
+		}
+		if len(node.children) > 0 {
+			renderSubtestCounts(node, output, failStyle, metricStyle)
+		}
+		output.WriteString("\n")
+
+		// Show the selected test's output right under its row
+		if test.Name == selectedTest {
+			renderSelectedTestOutput(test, output, normalStyle, metricStyle)
+		}
+	}
+}
+
+// renderSubtestCounts renders how the subtests below a parent did, so failures
+// stay visible while the parent is collapsed
+func renderSubtestCounts(node *testTreeNode, output *strings.Builder, failStyle, metricStyle lipgloss.Style) {
+	output.WriteString(metricStyle.Render(fmt.Sprintf("  %d/%d subtests passed", node.passed, node.subtests())))
+	if node.failed > 0 {
+		output.WriteString(failStyle.Render(fmt.Sprintf(", %d failed", node.failed)))
+	}
+	if node.flaky > 0 {
+		output.WriteString(flakyStyle.Render(fmt.Sprintf(", %d flaky", node.flaky)))
+	}
+	if node.skipped > 0 {
+		output.WriteString(metricStyle.Render(fmt.Sprintf(", %d skipped", node.skipped)))
+	}
+}
+
+// renderSelectedTestOutput renders the output of the test under the cursor
+func renderSelectedTestOutput(test TestResult, output *strings.Builder, normalStyle, metricStyle lipgloss.Style) {
+	trimmed := strings.TrimSpace(test.Output)
+	if trimmed == "" {
+		output.WriteString(metricStyle.Render("         │ (no output)") + "\n")
+		return
+	}
+	for _, line := range strings.Split(trimmed, "\n") {
+		output.WriteString(metricStyle.Render("         │ ") + normalStyle.Render(strings.TrimRight(line, " \t")) + "\n")
+	}
+}
+
+// renderFailureOutput renders the output of a failed test, highlighting
+// assertion messages and file locations
+// A panic is summarized with its first frame in the module at modulePath
+func renderFailureOutput(testOutput string, modulePath string, output *strings.Builder, failStyle, normalStyle lipgloss.Style) {
+	if testOutput == "" {
+		output.WriteString(normalStyle.Render("  (No failure details captured)") + "\n")
+		return
+	}
+
+	// Format failure output more clearly
+	lines := strings.Split(strings.TrimSpace(testOutput), "\n")
+	inRace := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		// Race reports are parsed separately - show a single line in their place
+		if inRace {
+			inRace = trimmed != raceDelimiter
+			continue
+		}
+		if trimmed == raceDelimiter && i+1 < len(lines) && strings.TrimSpace(lines[i+1]) == raceWarning {
+			inRace = true
+			output.WriteString(failStyle.Render("  >>> ") + normalStyle.Render("DATA RACE (press D for the race view)") + "\n")
+			continue
+		}
+
+		// A panic ends the output with a stack dump - summarize it instead
+		// A line that only looks like a panic has no dump after it and is shown as is
+		if isPanicLine(trimmed) && panicDumpFollows(lines[i+1:]) {
+			if report := parsePanic(strings.Join(lines[i:], "\n")); report != nil {
+				output.WriteString(failStyle.Render("  >>> ") + normalStyle.Render("panic: "+report.Message) + "\n")
+				if first := firstModuleFrame(report.Goroutine.Frames, modulePath); first >= 0 {
+					frame := report.Goroutine.Frames[first]
+					output.WriteString(normalStyle.Render("  at: "+frame.Function+" ("+frame.Location()+")") + "\n")
+				}
+				output.WriteString(normalStyle.Render("      (press P for the full stack)") + "\n")
+			}
+			break
+		}
+
+		// Highlight common assertion patterns
+		if strings.HasPrefix(trimmed, "--- run ") {
+			// Separator between the failing runs of a repeated test
+			output.WriteString(failStyle.Render("  "+trimmed) + "\n")
+		} else if strings.Contains(trimmed, "Error:") || strings.Contains(trimmed, "error:") {
+			output.WriteString(failStyle.Render("  >>> ") + normalStyle.Render(trimmed) + "\n")
+		} else if strings.Contains(trimmed, "want") || strings.Contains(trimmed, "got") {
+			output.WriteString(normalStyle.Render("      "+trimmed) + "\n")
+		} else if strings.Contains(trimmed, ".go:") {
+			// Likely a file location
+			output.WriteString(normalStyle.Render("  at: "+trimmed) + "\n")
+		} else {
+			output.WriteString(normalStyle.Render("      "+trimmed) + "\n")
+		}
+	}
+}
+
+// testCursorLine returns the line of formatted details output holding the test
+// cursor, or -1 if no test is selected
+func testCursorLine(content string) int {
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, testCursorMarker+"[") {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderTestProgress renders tests of a package that is still executing
+// Running tests are listed first, followed by completed tests in the order they started
+// The row of selectedTest is marked with the cursor and followed by its output so far
+func renderTestProgress(tests []TestResult, output *strings.Builder, passStyle, failStyle, normalStyle, metricStyle lipgloss.Style, selectedTest string) {
+	if len(tests) == 0 {
+		output.WriteString(normalStyle.Render("Waiting for tests to start...") + "\n")
+		return
+	}
+
+	var running, completed []TestResult
+	for _, test := range tests {
+		if test.Status == "RUNNING" {
+			running = append(running, test)
+		} else {
+			completed = append(completed, test)
+		}
+	}
+
+	if len(running) > 0 {
+		output.WriteString(normalStyle.Render("In Progress:") + "\n")
+		for _, test := range running {
+			prefix := "  "
+			if test.Name == selectedTest {
+				prefix = testCursorMarker
+			}
+			label := metricStyle.Render(prefix + "[RUN ] ")
+			if test.Hung {
+				label = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff8800")).Render(prefix + "[HANG] ")
+			}
+			elapsed := ""
+			if !test.Started.IsZero() {
+				elapsed = fmt.Sprintf(" %8s", time.Since(test.Started).Truncate(time.Second))
+			}
+			output.WriteString(label + normalStyle.Render(fmt.Sprintf("%-45s", test.Name)) + metricStyle.Render(elapsed) + "\n")
+			if test.Name == selectedTest {
+				renderSelectedTestOutput(test, output, normalStyle, metricStyle)
+			}
+		}
+		output.WriteString("\n")
 	}
 
-	// Show passes (slowest first)
-	for _, test := range passed {
-		output.WriteString(passStyle.Render("  [PASS] ") +
-			normalStyle.Render(fmt.Sprintf("%-45s", test.Name)) +
-			metricStyle.Render(fmt.Sprintf(" %8s", formatDuration(test.Duration))) + "\n")
+	if len(completed) > 0 {
+		output.WriteString(normalStyle.Render("Completed:") + "\n")
+		for _, test := range completed {
+			prefix := "  "
+			if test.Name == selectedTest {
+				prefix = testCursorMarker
+			}
+			var label string
+			switch test.Status {
+			case "PASS":
+				label = passStyle.Render(prefix + "[PASS] ")
+			case "FAIL":
+				label = failStyle.Render(prefix + "[FAIL] ")
+			default:
+				label = normalStyle.Render(prefix + "[SKIP] ")
+			}
+			output.WriteString(label +
+				normalStyle.Render(fmt.Sprintf("%-45s", test.Name)) +
+				metricStyle.Render(fmt.Sprintf(" %8s", formatDuration(test.Duration))) + "\n")
+			if test.Name == selectedTest {
+				renderSelectedTestOutput(test, output, normalStyle, metricStyle)
+			}
+		}
 	}
+}
 
-	// Show skipped
-	for _, test := range skipped {
-		output.WriteString(normalStyle.Render(fmt.Sprintf("  [SKIP] %-45s (skipped)", test.Name)) + "\n")
+// styledStatus renders a package status with a color matching its outcome
+func styledStatus(status string, theme Theme) string {
+	switch status {
+	case "PASS":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00")).Render("PASS")
+	case "RUNNING":
+		return lipgloss.NewStyle().Foreground(theme.MenuActiveFg).Render("RUNNING")
+	case "HANG":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff8800")).Render("HANG?")
+	case "FLAKY":
+		return flakyStyle.Render("FLAKY")
+	case buildFailStatus:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Bold(true).Render(buildFailStatus)
+	case "CANCELLED":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ffff00")).Render("CANCELLED")
+	case "QUEUED":
+		return lipgloss.NewStyle().Foreground(theme.HelpColor).Render("QUEUED")
+	case "ERROR":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Render("ERROR")
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Render("FAIL")
+	}
+}
+
+// renderFailureKind renders why a failed run failed, below its status
+func renderFailureKind(result *PackageTestResult, output *strings.Builder, normalStyle lipgloss.Style) {
+	if result.FailureKind == FailureNone {
+		return
+	}
+	output.WriteString(normalStyle.Render("Failure: ") +
+		failureKindStyle.Render(string(result.FailureKind)) +
+		normalStyle.Render(" - "+result.FailureDetail) + "\n")
+}
+
+// renderRunDetails renders how the run differed from a plain single run: how
+// often each test ran and how many were flaky, and the shuffle seed
+func renderRunDetails(result *PackageTestResult, output *strings.Builder, normalStyle, metricStyle lipgloss.Style) {
+	if result.RunCount > 1 {
+		output.WriteString(normalStyle.Render("Runs: ") +
+			metricStyle.Render(fmt.Sprintf("%d per test", result.RunCount)) +
+			normalStyle.Render(" | Flaky: ") +
+			flakyStyle.Render(fmt.Sprintf("%d", result.FlakyTests)) + "\n")
+	}
+	if result.ShuffleSeed != "" {
+		output.WriteString(normalStyle.Render("Shuffle Seed: ") +
+			metricStyle.Render(result.ShuffleSeed) +
+			normalStyle.Render(" (press s in test details to replay this order)") + "\n")
+	}
+	var profiles []string
+	if result.CPUProfile != "" {
+		profiles = append(profiles, "CPU")
+	}
+	if result.MemProfile != "" {
+		profiles = append(profiles, "memory")
+	}
+	if len(profiles) > 0 {
+		output.WriteString(normalStyle.Render("Profiled: ") +
+			metricStyle.Render(strings.Join(profiles, " and ")) +
+			normalStyle.Render(" (press T in test details for the top functions)") + "\n")
+	}
+	if result.CoverPkg != "" {
+		from := "no test package"
+		if len(result.CoverageFrom) > 0 {
+			from = strings.Join(result.CoverageFrom, ", ")
+		}
+		output.WriteString(normalStyle.Render("Covered By: ") +
+			metricStyle.Render(from) +
+			normalStyle.Render(" (-coverpkg="+result.CoverPkg+")") + "\n")
 	}
 }
 
@@ -57,8 +399,6 @@ func FormatTestResultSummary(result *PackageTestResult, theme Theme, selectedBut
 	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
 	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
 	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
-	passStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00"))
-	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
 
 	separator := separatorStyle.Render("========================================")
 	output.WriteString(separator + "\n")
@@ -66,17 +406,13 @@ func FormatTestResultSummary(result *PackageTestResult, theme Theme, selectedBut
 	output.WriteString(separator + "\n")
 
 	// Status summary with color based on pass/fail
-	var statusStyled string
-	if result.Status == "PASS" {
-		statusStyled = passStyle.Render("PASS")
-	} else {
-		statusStyled = failStyle.Render("FAIL")
-	}
-	output.WriteString(normalStyle.Render("Status: ") + statusStyled +
+	output.WriteString(normalStyle.Render("Status: ") + styledStatus(result.Status, theme) +
 		normalStyle.Render(fmt.Sprintf(" (%d/%d passed)", result.PassedTests, result.TotalTests)) + "\n")
+	renderFailureKind(result, &output, normalStyle)
 
 	output.WriteString(normalStyle.Render("Coverage: ") +
 		metricStyle.Render(fmt.Sprintf("%.1f%%", result.Coverage)) + "\n")
+	renderRunDetails(result, &output, normalStyle, metricStyle)
 
 	// Calculate sum of individual test times
 	var testTimeSum time.Duration
@@ -85,7 +421,15 @@ func FormatTestResultSummary(result *PackageTestResult, theme Theme, selectedBut
 	}
 
 	// Show time breakdown
-	if result.Duration > 0 {
+	if result.Status == "RUNNING" {
+		output.WriteString(normalStyle.Render("Elapsed Time: ") +
+			metricStyle.Render("(in progress)") + "\n")
+		if hung := hungTestCount(result); hung > 0 {
+			output.WriteString(normalStyle.Render("Possibly Hung: ") + styledStatus("HANG", theme) +
+				normalStyle.Render(fmt.Sprintf(" %d test(s) over the hang threshold", hung)) + "\n")
+		}
+		output.WriteString("\n")
+	} else if result.Duration > 0 {
 		setupTime := result.Duration - testTimeSum
 		output.WriteString(normalStyle.Render("Elapsed Time: ") +
 			metricStyle.Render(formatDuration(result.Duration)) +
@@ -291,7 +635,10 @@ func renderProgressBar(percentage float64, theme Theme) string {
 }
 
 // FormatTestResult formats a test result for display with theme styling
-func FormatTestResult(result *PackageTestResult, theme Theme) string {
+// modulePath is the scanned module's path, for the first project frame of panics
+// selectedTest names the test under the cursor ("" for none) and expanded the
+// parents toggled open or closed in the subtest tree
+func FormatTestResult(result *PackageTestResult, modulePath string, theme Theme, selectedTest string, expanded map[string]bool) string {
 	var output strings.Builder
 
 	// Styles
@@ -307,7 +654,10 @@ func FormatTestResult(result *PackageTestResult, theme Theme) string {
 	output.WriteString(separator + "\n")
 
 	if result.Status == "RUNNING" {
-		output.WriteString(normalStyle.Render("Status: Running tests...") + "\n\n")
+		output.WriteString(normalStyle.Render("Status: ") + styledStatus(result.Status, theme) +
+			normalStyle.Render(fmt.Sprintf(" (%d passed, %d failed, %d skipped so far)",
+				result.PassedTests, result.FailedTests, result.SkippedTests)) + "\n\n")
+		renderTestProgress(result.Tests, &output, passStyle, failStyle, normalStyle, metricStyle, selectedTest)
 		return output.String()
 	}
 
@@ -318,17 +668,13 @@ func FormatTestResult(result *PackageTestResult, theme Theme) string {
 	}
 
 	// Status summary with color based on pass/fail
-	var statusStyled string
-	if result.Status == "PASS" {
-		statusStyled = passStyle.Render("PASS")
-	} else {
-		statusStyled = failStyle.Render("FAIL")
-	}
-	output.WriteString(normalStyle.Render("Status: ") + statusStyled +
+	output.WriteString(normalStyle.Render("Status: ") + styledStatus(result.Status, theme) +
 		normalStyle.Render(fmt.Sprintf(" (%d/%d passed)", result.PassedTests, result.TotalTests)) + "\n")
+	renderFailureKind(result, &output, normalStyle)
 
 	output.WriteString(normalStyle.Render("Coverage: ") +
 		metricStyle.Render(fmt.Sprintf("%.1f%%", result.Coverage)) + "\n")
+	renderRunDetails(result, &output, normalStyle, metricStyle)
 
 	// Calculate sum of individual test times
 	var testTimeSum time.Duration
@@ -345,7 +691,7 @@ func FormatTestResult(result *PackageTestResult, theme Theme) string {
 			metricStyle.Render(formatDuration(testTimeSum)) +
 			normalStyle.Render(" | setup: ") +
 			metricStyle.Render(formatDuration(setupTime)) +
-			normalStyle.Render("]") + "\n\n")
+			normalStyle.Render("]  (press L for the timeline)") + "\n\n")
 	} else {
 		output.WriteString(normalStyle.Render("Total Time: ") +
 			metricStyle.Render("(cached)") + "\n\n")
@@ -358,74 +704,126 @@ func FormatTestResult(result *PackageTestResult, theme Theme) string {
 
 		for _, test := range result.Tests {
 			if test.Status == "FAIL" {
+				runs := ""
+				if test.Runs > 1 {
+					runs = fmt.Sprintf(", failed %d/%d runs", test.Failures, test.Runs)
+				}
 				output.WriteString(failStyle.Render("[ FAIL ] ") +
 					normalStyle.Render(test.Name) +
-					metricStyle.Render(fmt.Sprintf(" (%s)", formatDuration(test.Duration))) + "\n")
+					metricStyle.Render(fmt.Sprintf(" (%s%s)", formatDuration(test.Duration), runs)) + "\n")
 				output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
 
-				if test.Output != "" {
-					// Format failure output more clearly
-					lines := strings.Split(strings.TrimSpace(test.Output), "\n")
-					for _, line := range lines {
-						trimmed := strings.TrimSpace(line)
-						if trimmed == "" {
-							continue
-						}
-
-						// Highlight common assertion patterns
-						if strings.Contains(trimmed, "Error:") || strings.Contains(trimmed, "error:") {
-							output.WriteString(failStyle.Render("  >>> ") + normalStyle.Render(trimmed) + "\n")
-						} else if strings.Contains(trimmed, "want") || strings.Contains(trimmed, "got") {
-							output.WriteString(normalStyle.Render("      "+trimmed) + "\n")
-						} else if strings.Contains(trimmed, ".go:") {
-							// Likely a file location
-							output.WriteString(normalStyle.Render("  at: "+trimmed) + "\n")
-						} else {
-							output.WriteString(normalStyle.Render("      "+trimmed) + "\n")
-						}
-					}
+				// A failed example shows how its output differs from the // Output: comment
+				if mismatch := parseExampleMismatch(test.Output); isExampleTest(test.Name) && mismatch != nil {
+					renderExampleDiff(mismatch, &output, failStyle, passStyle, normalStyle, metricStyle)
 				} else {
-					output.WriteString(normalStyle.Render("  (No failure details captured)") + "\n")
+					renderFailureOutput(test.Output, modulePath, &output, failStyle, normalStyle)
 				}
 				output.WriteString("\n")
 			}
 		}
 	}
 
-	// Summary of all tests - separate unit and integration
-	if len(result.Tests) > 0 {
-		// Group by test type and status
-		var unitTests, integrationTests []TestResult
+	// Flaky tests - passed in some runs and failed in others
+	if result.FlakyTests > 0 {
+		flakyBox := separatorStyle.Render("┌─────────────────────────────────────────┐\n│            FLAKY TESTS                  │\n└─────────────────────────────────────────┘")
+		output.WriteString(flakyBox + "\n\n")
+
 		for _, test := range result.Tests {
-			if test.TestType == "integration" {
-				integrationTests = append(integrationTests, test)
-			} else {
-				unitTests = append(unitTests, test)
+			if test.Status == "FLAKY" {
+				output.WriteString(flakyStyle.Render("[FLAKY] ") +
+					normalStyle.Render(test.Name) +
+					metricStyle.Render(fmt.Sprintf(" (failed %d/%d runs, %.0f%%)", test.Failures, test.Runs, test.FailureRate()*100)) + "\n")
+				output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
+				renderFailureOutput(test.Output, modulePath, &output, failStyle, normalStyle)
+				output.WriteString("\n")
 			}
 		}
+	}
 
-		// Show unit tests section if any exist
-		if len(unitTests) > 0 {
-			output.WriteString(normalStyle.Render("Unit Tests:") + "\n")
-			output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
-			renderTestGroup(unitTests, &output, passStyle, failStyle, normalStyle, metricStyle)
-			output.WriteString("\n")
+	// Data races, deduplicated across tests - both stacks are shown in the race view
+	if len(result.Races) > 0 {
+		raceBox := separatorStyle.Render("┌─────────────────────────────────────────┐\n│            DATA RACES                   │\n└─────────────────────────────────────────┘")
+		output.WriteString(raceBox + "\n\n")
+
+		for _, race := range result.Races {
+			where := ""
+			if race.Count > 1 {
+				where = fmt.Sprintf(" ×%d", race.Count)
+			}
+			if len(race.Tests) > 0 {
+				where += " in " + strings.Join(race.Tests, ", ")
+			}
+			output.WriteString(failStyle.Render("[RACE] ") +
+				normalStyle.Render(fmt.Sprintf("%s %s ↔ %s %s",
+					strings.ToLower(race.Access.Op), shortLocation(race.Access.Location()),
+					strings.ToLower(race.Previous.Op), shortLocation(race.Previous.Location()))) +
+				metricStyle.Render(where) + "\n")
 		}
+		output.WriteString(normalStyle.Render("Press D to compare the conflicting stacks side by side") + "\n\n")
+	}
 
-		// Show integration tests section if any exist
-		if len(integrationTests) > 0 {
-			output.WriteString(normalStyle.Render("Integration Tests:") + "\n")
+	// Tests that started but never reported a result (package cancelled or killed)
+	var unfinished []TestResult
+	for _, test := range result.Tests {
+		if test.Status == "RUNNING" {
+			unfinished = append(unfinished, test)
+		}
+	}
+	if result.Timeout != nil {
+		// go test -timeout panicked - list the tests it reported as still running
+		output.WriteString(failStyle.Render(fmt.Sprintf("Timed out after %s (still running when go test panicked):", result.Timeout.After)) + "\n")
+		output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
+		for _, running := range result.Timeout.RunningTests {
+			output.WriteString(failStyle.Render("  [TIME] ") +
+				normalStyle.Render(fmt.Sprintf("%-45s", running.Name)) +
+				metricStyle.Render(fmt.Sprintf(" %8s", running.Duration)) + "\n")
+		}
+		output.WriteString(normalStyle.Render(fmt.Sprintf("  Goroutine dump: %d goroutines", len(result.Timeout.Goroutines))) +
+			metricStyle.Render(" (press d to browse)") + "\n\n")
+	} else if len(unfinished) > 0 {
+		output.WriteString(normalStyle.Render("Unfinished (still running when the package stopped):") + "\n")
+		output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
+		for _, test := range unfinished {
+			output.WriteString(metricStyle.Render("  [----] ") + normalStyle.Render(test.Name) + "\n")
+		}
+		output.WriteString("\n")
+	}
+
+	// Summary of all tests - one section per test type (unit, then each tag set)
+	if len(result.Tests) > 0 {
+		// Group by test type and status
+		for i, group := range groupTestsByType(result.Tests) {
+			if i > 0 {
+				output.WriteString("\n")
+			}
+			output.WriteString(normalStyle.Render(testTypeLabel(group.testType)+":") + "\n")
 			output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
-			renderTestGroup(integrationTests, &output, passStyle, failStyle, normalStyle, metricStyle)
+			renderTestGroup(group.tests, &output, passStyle, failStyle, normalStyle, metricStyle, selectedTest, expanded)
 		}
 
 	} else {
 		// No tests found - show the actual output to help debug
 		output.WriteString("\n")
 		noTestsBox := separatorStyle.Render("┌─────────────────────────────────────────┐\n│       NO TESTS PARSED                   │\n└─────────────────────────────────────────┘")
+		if result.Status == buildFailStatus {
+			noTestsBox = separatorStyle.Render("┌─────────────────────────────────────────┐\n│       BUILD FAILED                      │\n└─────────────────────────────────────────┘")
+		}
 		output.WriteString(noTestsBox + "\n\n")
 
-		if result.Status == "FAIL" {
+		if result.Status == "CANCELLED" {
+			output.WriteString(normalStyle.Render("The run was cancelled before any tests reported results.") + "\n")
+		} else if result.Status == buildFailStatus && len(result.BuildErrors) > 0 {
+			output.WriteString(failStyle.Render(fmt.Sprintf("The test binary failed to build (%d error(s)):", len(result.BuildErrors))) + "\n")
+			output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
+			for _, diagnostic := range result.BuildErrors {
+				output.WriteString(metricStyle.Render("  "+shortLocation(diagnostic.Location())) + "\n")
+				for _, line := range strings.Split(diagnostic.Message, "\n") {
+					output.WriteString(normalStyle.Render("      "+line) + "\n")
+				}
+			}
+			output.WriteString("\n" + normalStyle.Render("Press B to see the errors in their source") + "\n")
+		} else if result.Status == "FAIL" || result.Status == buildFailStatus {
 			output.WriteString(normalStyle.Render("The test command failed. Raw output:") + "\n")
 			output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
 			// Show first 30 lines of output to help debug
@@ -447,24 +845,13 @@ func FormatTestResult(result *PackageTestResult, theme Theme) string {
 	// Per-file coverage breakdown
 	if len(result.FileCoverages) > 0 {
 		output.WriteString("\n")
-		output.WriteString(normalStyle.Render("Per-File Coverage:") + "\n")
+		output.WriteString(normalStyle.Render("Per-File Coverage:") + metricStyle.Render(" (press C to view the source of a file)") + "\n")
 		output.WriteString(separatorStyle.Render("-------------------------------------------") + "\n")
 
 		for _, fc := range result.FileCoverages {
 			// Color-code based on coverage
-			// [!!] = poor (<50%), [**] = medium (50-80%), [++] = good (>=80%)
-			var indicatorStyled string
-			var coverageColor lipgloss.Style
-			if fc.CoveragePercent >= 80.0 {
-				coverageColor = passStyle
-				indicatorStyled = passStyle.Render("[++]") // Good coverage
-			} else if fc.CoveragePercent >= 50.0 {
-				coverageColor = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffff00")) // Yellow
-				indicatorStyled = coverageColor.Render("[**]")                            // Medium coverage
-			} else {
-				coverageColor = failStyle
-				indicatorStyled = failStyle.Render("[!!]") // Poor coverage
-			}
+			indicator, coverageColor := coverageIndicator(fc.CoveragePercent, passStyle, failStyle)
+			indicatorStyled := coverageColor.Render(indicator)
 
 			output.WriteString(fmt.Sprintf("  %s %s %s %s\n",
 				indicatorStyled,
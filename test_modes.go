@@ -0,0 +1,102 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// tagsModePrefix prefixes test modes that build with a discovered tag set,
+// e.g. "tags:e2e,slow"
+const tagsModePrefix = "tags:"
+
+// tagsMode returns the test mode that builds with the given tags
+// The integration tag on its own keeps its original mode name so existing configs still match
+func tagsMode(tags []string) testMode {
+	if len(tags) == 0 {
+		return testModeUnit
+	}
+	if len(tags) == 1 && tags[0] == "integration" {
+		return testModeIntegration
+	}
+	return testMode(tagsModePrefix + strings.Join(tags, ","))
+}
+
+// Tags returns the build tags the mode passes to go test
+// "all" mode has no fixed tags; it uses every tag of the package (see allModeTags)
+func (mode testMode) Tags() []string {
+	if mode == testModeIntegration {
+		return []string{"integration"}
+	}
+	if strings.HasPrefix(string(mode), tagsModePrefix) {
+		return splitTagList(strings.TrimPrefix(string(mode), tagsModePrefix))
+	}
+	return nil
+}
+
+// Label returns the display name of a mode
+func (mode testMode) Label() string {
+	switch mode {
+	case testModeUnit:
+		return "Unit"
+	case testModeIntegration:
+		return "Integration"
+	case testModeAll:
+		return "All"
+	}
+	return "Tags: " + strings.Join(mode.Tags(), ",")
+}
+
+// testTypeLabel returns the section heading for tests of a test type
+func testTypeLabel(testType string) string {
+	switch testType {
+	case "", "unit":
+		return "Unit Tests"
+	case "integration":
+		return "Integration Tests"
+	case exampleTestGroup:
+		return "Examples"
+	}
+	return "Tagged Tests (" + testType + ")"
+}
+
+// testModeOptions returns the modes that can be chosen for a package:
+// unit, one per discovered tag set, and all when the package has tagged tests
+// current is included even if its tags are no longer found so it stays selectable
+func testModeOptions(pkg TestPackage, current testMode) []testMode {
+	options := []testMode{testModeUnit}
+	for _, set := range pkg.TagSets {
+		options = append(options, tagsMode(set))
+	}
+	if len(pkg.TagSets) > 0 {
+		options = append(options, testModeAll)
+	}
+
+	found := false
+	for _, mode := range options {
+		if mode == current {
+			found = true
+			break
+		}
+	}
+	if !found {
+		options = append(options, current)
+	}
+	return options
+}
+
+// allModeTags returns the union of the tag sets of packages, used by "all" mode
+// to build every test file at once
+func allModeTags(packages ...TestPackage) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, pkg := range packages {
+		for _, tag := range pkg.BuildTags() {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -15,83 +15,260 @@ import (
 	"time"
 )
 
-// parseTestOutput parses go test -json output to extract test results
-func parseTestOutput(result *PackageTestResult, output string) {
-	scanner := bufio.NewScanner(strings.NewReader(output))
+// coverageRegex matches the package-level coverage summary line
+var coverageRegex = regexp.MustCompile(`coverage: (\d+\.\d+)% of statements`)
+
+// shuffleSeedRegex matches the seed line a test binary prints when run with -shuffle
+var shuffleSeedRegex = regexp.MustCompile(`^-test\.shuffle (-?\d+)`)
+
+// testEventParser incrementally builds a PackageTestResult from go test -json events
+// Events can be fed one at a time as they arrive, so a partially built result is
+// always available for display while the package is still executing
+type testEventParser struct {
+	result        *PackageTestResult
+	testOutputs   map[string]*strings.Builder // Output collected per running test
+	testIndex     map[string]int              // Test name -> index of its latest entry in result.Tests
+	outcome       string                      // Package-level action once the package finishes ("pass", "fail", "skip")
+	timeoutDump   *strings.Builder            // Output from a timeout panic on, nil until one starts
+	panicDump     *strings.Builder            // Output from a test panic on, nil until one starts
+	panicTest     string                      // Test that panicked
+	panicFrom     string                      // Test whose output the panic line was in
+	failChain     []string                    // Tests failed since the last test started, innermost first
+	packageOutput strings.Builder             // Output not attributed to a test
+	buildOutput   map[string]*strings.Builder // Build output per import path being built
+	failedBuild   string                      // Import path whose build failure failed the package, if any
+	vetFailed     bool                        // The failed build was go vet's, not the compiler's
+	signal        string                      // Signal that killed the test binary, e.g. "killed"
+	signalTest    string                      // Test running when the signal arrived
+}
 
-	// Track test outputs by test name
-	testOutputs := make(map[string]*strings.Builder)
+// newTestEventParser creates a parser that accumulates events into result
+func newTestEventParser(result *PackageTestResult) *testEventParser {
+	return &testEventParser{
+		result:      result,
+		testOutputs: make(map[string]*strings.Builder),
+		testIndex:   make(map[string]int),
+		buildOutput: make(map[string]*strings.Builder),
+	}
+}
 
-	// Regex for coverage in output lines
-	coverageRegex := regexp.MustCompile(`coverage: (\d+\.\d+)% of statements`)
+// decodeTestEvent decodes a single line of go test -json output
+// Returns false for lines that are not JSON events
+func decodeTestEvent(line string) (TestEvent, bool) {
+	var event TestEvent
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		// Skip non-JSON lines (shouldn't happen with -json, but be safe)
+		return event, false
+	}
+	return event, true
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+// eventTime returns when an event happened, or now for events without a timestamp
+func eventTime(event TestEvent) time.Time {
+	if event.Time.IsZero() {
+		return time.Now()
+	}
+	return event.Time
+}
 
-		// Parse JSON event
-		var event TestEvent
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			// Skip non-JSON lines (shouldn't happen with -json, but be safe)
-			continue
+// Consume applies a single test event to the result being built
+func (p *testEventParser) Consume(event TestEvent) {
+	result := p.result
+
+	switch event.Action {
+	case "build-output":
+		// Compiler output, reported per import path before the package starts
+		builder, ok := p.buildOutput[event.ImportPath]
+		if !ok {
+			builder = &strings.Builder{}
+			p.buildOutput[event.ImportPath] = builder
+		}
+		builder.WriteString(event.Output)
+
+	case "start":
+		// The test binary started running
+		if event.Test == "" {
+			result.Started = eventTime(event)
+		}
+
+	case "run":
+		// Test started - record it as running and initialize output collector
+		if event.Test != "" {
+			p.failChain = nil
+			p.testOutputs[event.Test] = &strings.Builder{}
+			result.Tests = append(result.Tests, TestResult{
+				Name:    event.Test,
+				Status:  "RUNNING",
+				Started: eventTime(event),
+			})
+			p.testIndex[event.Test] = len(result.Tests) - 1
 		}
 
-		switch event.Action {
-		case "run":
-			// Test started - initialize output collector
-			if event.Test != "" {
-				testOutputs[event.Test] = &strings.Builder{}
+	case "pause", "cont":
+		// A t.Parallel test waits (pause) until its parent's sequential part is done (cont)
+		if idx, running := p.testIndex[event.Test]; running {
+			test := &result.Tests[idx]
+			if event.Action == "pause" {
+				test.Pauses = append(test.Pauses, TimeSpan{Start: eventTime(event)})
+			} else if n := len(test.Pauses); n > 0 && test.Pauses[n-1].End.IsZero() {
+				test.Pauses[n-1].End = eventTime(event)
 			}
+		}
 
-		case "output":
-			// Collect output for test or check for coverage
-			if event.Test != "" {
-				// Test-specific output
-				if builder, ok := testOutputs[event.Test]; ok {
-					builder.WriteString(event.Output)
-				}
-			} else {
-				// Package-level output - check for coverage
-				if matches := coverageRegex.FindStringSubmatch(event.Output); matches != nil {
-					coverage, _ := strconv.ParseFloat(matches[1], 64)
-					result.Coverage = coverage
-				}
+	case "output":
+		// A timeout panic and its goroutine dump run until the package ends,
+		// spread over the output of whichever tests were running
+		if p.timeoutDump == nil && strings.HasPrefix(event.Output, timeoutPanicPrefix) {
+			p.timeoutDump = &strings.Builder{}
+		}
+		if p.timeoutDump != nil {
+			p.timeoutDump.WriteString(event.Output)
+		}
+
+		// A panic is printed after the testing package has failed the panicking
+		// test and its parents, so the innermost of those is the one that panicked
+		if p.panicDump == nil && isPanicLine(event.Output) {
+			p.panicDump = &strings.Builder{}
+			p.panicTest = event.Test
+			p.panicFrom = event.Test
+			if len(p.failChain) > 0 {
+				p.panicTest = p.failChain[0]
 			}
+		}
+		if p.panicDump != nil {
+			p.panicDump.WriteString(event.Output)
+		}
+		if matches := signalRegex.FindStringSubmatch(strings.TrimRight(event.Output, "\n")); matches != nil && p.signal == "" {
+			p.signal = matches[1]
+			p.signalTest = event.Test
+		}
 
-		case "pass", "fail", "skip":
-			if event.Test != "" {
-				// Individual test completed
-				status := strings.ToUpper(event.Action)
-				testResult := TestResult{
-					Name:     event.Test,
-					Status:   status,
-					Duration: time.Duration(event.Elapsed * float64(time.Second)),
-				}
+		// Collect output for test or check for coverage
+		if event.Test != "" {
+			// Test-specific output
+			// Running tests get it on completion, or through exposeOutput to watch it live
+			if builder, ok := p.testOutputs[event.Test]; ok {
+				builder.WriteString(event.Output)
+			}
+		} else {
+			p.packageOutput.WriteString(event.Output)
+			// Package-level output - check for coverage and the shuffle seed
+			if matches := coverageRegex.FindStringSubmatch(event.Output); matches != nil {
+				coverage, _ := strconv.ParseFloat(matches[1], 64)
+				result.Coverage = coverage
+			}
+			if matches := shuffleSeedRegex.FindStringSubmatch(event.Output); matches != nil && result.ShuffleSeed == "" {
+				result.ShuffleSeed = matches[1]
+			}
+		}
 
-				// Attach collected output
-				if builder, ok := testOutputs[event.Test]; ok {
-					testResult.Output = builder.String()
-					delete(testOutputs, event.Test) // Clean up
-				}
+	case "pass", "fail", "skip":
+		if event.Test != "" {
+			// Individual test completed
+			status := strings.ToUpper(event.Action)
+			idx, ok := p.testIndex[event.Test]
+			if !ok {
+				// Completion without a preceding run event - add the entry now
+				result.Tests = append(result.Tests, TestResult{Name: event.Test})
+				idx = len(result.Tests) - 1
+			}
 
-				result.Tests = append(result.Tests, testResult)
-				result.TotalTests++
+			test := &result.Tests[idx]
+			test.Status = status
+			if p.panicDump != nil && event.Test == p.panicFrom && status != "FAIL" {
+				// A panic ends the test binary, so the test only printed a line
+				// that looks like one
+				p.panicDump = nil
+			}
+			test.Duration = time.Duration(event.Elapsed * float64(time.Second))
+			test.Ended = eventTime(event)
 
-				switch status {
-				case "PASS":
-					result.PassedTests++
-				case "FAIL":
-					result.FailedTests++
-				case "SKIP":
-					result.SkippedTests++
+			// Attach collected output
+			if builder, ok := p.testOutputs[event.Test]; ok {
+				test.Output = builder.String()
+				delete(p.testOutputs, event.Test) // Clean up
+			}
+			test.Races = parseDataRaces(test.Output)
+			for i := range test.Races {
+				test.Races[i].Tests = []string{test.Name}
+			}
+			delete(p.testIndex, event.Test)
+
+			result.TotalTests++
+
+			switch status {
+			case "PASS":
+				result.PassedTests++
+			case "FAIL":
+				result.FailedTests++
+				p.failChain = append(p.failChain, event.Test)
+			case "SKIP":
+				result.SkippedTests++
+			}
+		} else {
+			// Package completed - record total duration and outcome
+			result.Duration = time.Duration(event.Elapsed * float64(time.Second))
+			result.Ended = eventTime(event)
+			p.outcome = event.Action
+			if p.timeoutDump != nil {
+				result.Timeout = parseTimeoutPanic(p.timeoutDump.String())
+			}
+			if p.panicDump != nil {
+				p.attachPanic(parsePanic(p.panicDump.String()), p.panicDump.String())
+			}
+			result.Races = collectRaces(result.Tests, parseDataRaces(p.packageOutput.String()))
+			if event.FailedBuild != "" {
+				p.failedBuild = event.FailedBuild
+				if builder, ok := p.buildOutput[event.FailedBuild]; ok {
+					result.BuildErrors = parseBuildDiagnostics(builder.String())
+					p.vetFailed = isVetOutput(builder.String())
 				}
-			} else {
-				// Package completed - record total duration
-				result.Duration = time.Duration(event.Elapsed * float64(time.Second))
 			}
 		}
 	}
 }
 
+// exposeOutput copies the output collected so far for a running test onto its
+// entry, so it can be watched live
+func (p *testEventParser) exposeOutput(testName string) {
+	builder, ok := p.testOutputs[testName]
+	if !ok {
+		return
+	}
+	if idx, running := p.testIndex[testName]; running {
+		p.result.Tests[idx].Output = builder.String()
+	}
+}
+
+// exposeRunningOutput copies the output of every test still running onto its
+// entry, for tests cut short by a timeout, a crash or cancellation
+func (p *testEventParser) exposeRunningOutput() {
+	for testName := range p.testIndex {
+		p.exposeOutput(testName)
+	}
+}
+
+// attachPanic records a parsed panic on the package and on the test that panicked
+// The panic is printed after that test completed, so its output is added to the test's
+func (p *testEventParser) attachPanic(report *PanicReport, output string) {
+	if report == nil {
+		return
+	}
+	report.Test = p.panicTest
+	p.result.Panic = report
+
+	for i := len(p.result.Tests) - 1; i >= 0; i-- {
+		test := &p.result.Tests[i]
+		if test.Name == report.Test {
+			test.Panic = report
+			if !strings.Contains(test.Output, output) {
+				test.Output += output
+			}
+			return
+		}
+	}
+}
+
 // parseCoverageProfile parses a go test coverage profile to extract per-file coverage
 func parseCoverageProfile(result *PackageTestResult, profilePath string) {
 	file, err := os.Open(profilePath)
@@ -102,6 +279,7 @@ func parseCoverageProfile(result *PackageTestResult, profilePath string) {
 
 	// Map of filename -> {covered statements, total statements}
 	fileCoverage := make(map[string][2]int)
+	fileBlocks := make(map[string][]coverageBlock) // Kept for the source view
 
 	scanner := bufio.NewScanner(file)
 	// Skip first line (mode: set/count/atomic)
@@ -124,6 +302,11 @@ func parseCoverageProfile(result *PackageTestResult, profilePath string) {
 		numStatements, _ := strconv.Atoi(matches[4])
 		covered, _ := strconv.Atoi(matches[5])
 
+		block := coverageBlock{numStmt: numStatements, count: covered}
+		block.startLine, block.startCol = parseLineColumn(matches[2])
+		block.endLine, block.endCol = parseLineColumn(matches[3])
+		fileBlocks[filename] = append(fileBlocks[filename], block)
+
 		stats := fileCoverage[filename]
 		stats[1] += numStatements // Total statements
 		if covered > 0 {
@@ -145,6 +328,7 @@ func parseCoverageProfile(result *PackageTestResult, profilePath string) {
 			FileName:        filename,
 			CoveredLines:    coveredLines,
 			TotalLines:      totalLines,
+			Blocks:          fileBlocks[filename],
 			CoveragePercent: percentage,
 		})
 	}
@@ -155,6 +339,14 @@ func parseCoverageProfile(result *PackageTestResult, profilePath string) {
 	})
 }
 
+// parseLineColumn parses a "line.column" position of a coverage profile
+func parseLineColumn(position string) (int, int) {
+	lineText, columnText, _ := strings.Cut(position, ".")
+	line, _ := strconv.Atoi(lineText)
+	column, _ := strconv.Atoi(columnText)
+	return line, column
+}
+
 // parseFunctionCoverage parses function-level coverage using go tool cover
 func parseFunctionCoverage(result *PackageTestResult, profilePath string, packageDir string) {
 	// Step 1: Parse go tool cover -func to get function definitions
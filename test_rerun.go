@@ -0,0 +1,268 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// failedTestNames returns the most specific failed (or flaky) tests of a result
+// A parent test that failed only because one of its subtests failed is left out,
+// so reruns target the failing subtests rather than the whole parent
+func failedTestNames(result *PackageTestResult) []string {
+	var failed []string
+	for _, test := range result.Tests {
+		if test.Status == "FAIL" || test.Status == "FLAKY" {
+			failed = append(failed, test.Name)
+		}
+	}
+
+	var leaves []string
+	for _, name := range failed {
+		hasFailedChild := false
+		for _, other := range failed {
+			if strings.HasPrefix(other, name+"/") {
+				hasFailedChild = true
+				break
+			}
+		}
+		if !hasFailedChild {
+			leaves = append(leaves, name)
+		}
+	}
+	return leaves
+}
+
+// buildRunPattern builds an anchored go test -run pattern for the given tests
+// go test matches each "/"-separated level of a test name separately and ORs
+// alternatives separated by a top-level "|", so every parent gets an alternative
+// of its escaped levels with an alternation of the requested names below it
+// Tests listed along with one of their parents are left to the parent's alternative
+func buildRunPattern(names []string) string {
+	requested := make(map[string]bool, len(names))
+	for _, name := range names {
+		requested[name] = true
+	}
+
+	byParent := make(map[string][]string)
+	var parents []string
+	for name := range requested {
+		if hasRequestedAncestor(name, requested) {
+			continue
+		}
+		parent, last := "", name
+		if slash := strings.LastIndex(name, "/"); slash >= 0 {
+			parent, last = name[:slash], name[slash+1:]
+		}
+		if _, exists := byParent[parent]; !exists {
+			parents = append(parents, parent)
+		}
+		byParent[parent] = append(byParent[parent], regexp.QuoteMeta(last))
+	}
+	sort.Strings(parents)
+
+	alternatives := make([]string, 0, len(parents))
+	for _, parent := range parents {
+		var levels []string
+		if parent != "" {
+			for _, level := range strings.Split(parent, "/") {
+				levels = append(levels, "^"+regexp.QuoteMeta(level)+"$")
+			}
+		}
+		leaves := byParent[parent]
+		sort.Strings(leaves)
+		levels = append(levels, "^("+strings.Join(leaves, "|")+")$")
+		alternatives = append(alternatives, strings.Join(levels, "/"))
+	}
+	return strings.Join(alternatives, "|")
+}
+
+// hasRequestedAncestor reports whether a parent test of name is in requested
+func hasRequestedAncestor(name string, requested map[string]bool) bool {
+	for _, ancestor := range testAncestors(name) {
+		if requested[ancestor] {
+			return true
+		}
+	}
+	return false
+}
+
+// restrictRunPattern limits a -run pattern to the top-level tests in names
+// In every alternative of the pattern the first level is replaced by an anchored
+// alternation of the names it matches and deeper levels are kept; alternatives
+// matching none of the names are dropped
+// Returns "" if the pattern matches none of the names
+func restrictRunPattern(pattern string, names []string) string {
+	var restricted []string
+	for _, alternative := range splitRunPattern(pattern, '|') {
+		levels := splitRunPattern(alternative, '/')
+		re, err := regexp.Compile(levels[0])
+		if err != nil {
+			continue
+		}
+		var matched []string
+		for _, name := range names {
+			if re.MatchString(name) {
+				matched = append(matched, regexp.QuoteMeta(name))
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		sort.Strings(matched)
+		levels[0] = "^(" + strings.Join(matched, "|") + ")$"
+		restricted = append(restricted, strings.Join(levels, "/"))
+	}
+	return strings.Join(restricted, "|")
+}
+
+// splitRunPattern splits a -run pattern at every sep outside brackets,
+// parentheses and escapes, the way go test splits it into alternatives ('|')
+// and levels ('/')
+func splitRunPattern(pattern string, sep byte) []string {
+	var parts []string
+	classDepth, parenDepth := 0, 0
+	start := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '[':
+			classDepth++
+		case ']':
+			classDepth = Max(classDepth-1, 0)
+		case '(':
+			if classDepth == 0 {
+				parenDepth++
+			}
+		case ')':
+			if classDepth == 0 {
+				parenDepth--
+			}
+		case '\\':
+			i++
+		case sep:
+			if classDepth == 0 && parenDepth == 0 {
+				parts = append(parts, pattern[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, pattern[start:])
+}
+
+// testKey identifies a test within a package result
+// Tests are told apart by type too: a test declared in both a //go:build !integration
+// file and a //go:build integration file is two tests with the same name
+type testKey struct {
+	name, testType string
+}
+
+// mergeTestResults merges the outcome of a partial rerun into an earlier result
+// Tests that were rerun replace their previous entries of the same name and type,
+// all other tests, the coverage and any package-level failure of the full run are kept
+func mergeTestResults(existing *PackageTestResult, rerun *PackageTestResult) *PackageTestResult {
+	merged := *existing
+	merged.Tests = make([]TestResult, len(existing.Tests))
+	copy(merged.Tests, existing.Tests)
+
+	index := make(map[testKey]int, len(merged.Tests))
+	for i, test := range merged.Tests {
+		index[testKey{test.Name, test.TestType}] = i
+	}
+
+	rerunNames := make(map[string]bool, len(rerun.Tests))
+	for _, test := range rerun.Tests {
+		if test.Status == "RUNNING" {
+			// Never finished in the rerun - keep the previous outcome
+			continue
+		}
+		rerunNames[test.Name] = true
+		key := testKey{test.Name, test.TestType}
+		if i, exists := index[key]; exists {
+			merged.Tests[i] = test
+		} else {
+			merged.Tests = append(merged.Tests, test)
+			index[key] = len(merged.Tests) - 1
+		}
+	}
+
+	recountTests(&merged)
+	// Package-level races of both runs are kept; collectRaces drops the per-test ones
+	packageRaces := append(existing.Races[:len(existing.Races):len(existing.Races)], rerun.Races...)
+	merged.Races = collectRaces(merged.Tests, packageRaces)
+	// Keep an earlier panic only while the test that panicked hasn't been rerun clean
+	merged.Panic = rerun.Panic
+	if merged.Panic == nil && existing.Panic != nil && (!rerunNames[existing.Panic.Test] || testPanicked(merged.Tests, existing.Panic.Test)) {
+		merged.Panic = existing.Panic
+	}
+	merged.FullOutput = existing.FullOutput + rerun.FullOutput
+	merged.BuildErrors = appendBuildDiagnostics(existing.BuildErrors, rerun.BuildErrors)
+
+	// A package-level failure of the earlier run (build, TestMain exit, init panic,
+	// timeout, signal) isn't cleared by running some of the package's tests again
+	packageFailed := existing.Status == buildFailStatus || (existing.Status == "FAIL" && existing.FailedTests == 0)
+
+	switch {
+	case rerun.Status == buildFailStatus:
+		merged.Status = buildFailStatus
+	case packageFailed:
+		merged.Status = existing.Status
+	case merged.FailedTests > 0, rerun.Status == "FAIL":
+		merged.Status = "FAIL"
+	case merged.FlakyTests > 0:
+		merged.Status = "FLAKY"
+	case rerun.Status == "CANCELLED":
+		merged.Status = existing.Status
+	default:
+		merged.Status = "PASS"
+	}
+
+	// A package-level failure of the earlier run or the rerun's own failure
+	// explains the merged one; otherwise it's the tests still failing
+	switch {
+	case rerun.Status == "CANCELLED", packageFailed && rerun.Status != buildFailStatus:
+		merged.FailureKind, merged.FailureDetail = existing.FailureKind, existing.FailureDetail
+	case rerun.FailureKind != FailureNone && rerun.FailureKind != FailureTests:
+		merged.FailureKind, merged.FailureDetail = rerun.FailureKind, rerun.FailureDetail
+	case merged.FailedTests > 0 || merged.FlakyTests > 0:
+		merged.FailureKind, merged.FailureDetail = FailureTests, testFailureDetail(&merged)
+	default:
+		merged.FailureKind, merged.FailureDetail = FailureNone, ""
+	}
+
+	return &merged
+}
+
+// testPanicked reports whether a test of the given name ended in a panic
+func testPanicked(tests []TestResult, name string) bool {
+	for _, test := range tests {
+		if test.Name == name && test.Panic != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// recountTests recomputes the test counters of a result from its tests
+func recountTests(result *PackageTestResult) {
+	result.TotalTests = 0
+	result.PassedTests = 0
+	result.FailedTests = 0
+	result.SkippedTests = 0
+	result.FlakyTests = 0
+
+	for _, test := range result.Tests {
+		switch test.Status {
+		case "PASS":
+			result.PassedTests++
+		case "FAIL":
+			result.FailedTests++
+		case "SKIP":
+			result.SkippedTests++
+		case "FLAKY":
+			result.FlakyTests++
+		default:
+			continue
+		}
+		result.TotalTests++
+	}
+}
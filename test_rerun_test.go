@@ -0,0 +1,233 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMergeTestResults(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   []TestResult
+		rerun      []TestResult
+		wantStatus string
+		want       map[testKey]string
+	}{
+		{
+			name: "same name in tagged and untagged files",
+			existing: []TestResult{
+				{Name: "TestDup", TestType: "integration", Status: "FAIL"},
+				{Name: "TestOther", TestType: "unit", Status: "PASS"},
+			},
+			rerun: []TestResult{
+				{Name: "TestDup", TestType: "unit", Status: "PASS"},
+			},
+			wantStatus: "FAIL",
+			want: map[testKey]string{
+				{"TestDup", "integration"}: "FAIL",
+				{"TestDup", "unit"}:        "PASS",
+				{"TestOther", "unit"}:      "PASS",
+			},
+		},
+		{
+			name: "rerun replaces the test of the same type",
+			existing: []TestResult{
+				{Name: "TestFlaky", TestType: "unit", Status: "FAIL"},
+				{Name: "TestFlaky/case", TestType: "unit", Status: "FAIL"},
+			},
+			rerun: []TestResult{
+				{Name: "TestFlaky", TestType: "unit", Status: "PASS"},
+				{Name: "TestFlaky/case", TestType: "unit", Status: "PASS"},
+			},
+			wantStatus: "PASS",
+			want: map[testKey]string{
+				{"TestFlaky", "unit"}:      "PASS",
+				{"TestFlaky/case", "unit"}: "PASS",
+			},
+		},
+		{
+			name: "unfinished rerun keeps the earlier outcome",
+			existing: []TestResult{
+				{Name: "TestSlow", TestType: "unit", Status: "FAIL"},
+			},
+			rerun: []TestResult{
+				{Name: "TestSlow", TestType: "unit", Status: "RUNNING"},
+			},
+			wantStatus: "FAIL",
+			want: map[testKey]string{
+				{"TestSlow", "unit"}: "FAIL",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &PackageTestResult{PackagePath: "pkg", Status: "FAIL", Tests: tt.existing}
+			recountTests(existing)
+			rerun := &PackageTestResult{PackagePath: "pkg", Status: "PASS", Tests: tt.rerun}
+			recountTests(rerun)
+
+			merged := mergeTestResults(existing, rerun)
+			if merged.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", merged.Status, tt.wantStatus)
+			}
+			got := make(map[testKey]string, len(merged.Tests))
+			for _, test := range merged.Tests {
+				got[testKey{test.Name, test.TestType}] = test.Status
+			}
+			if len(got) != len(merged.Tests) {
+				t.Errorf("merged result has duplicate tests: %+v", merged.Tests)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d tests, want %d: %+v", len(got), len(tt.want), merged.Tests)
+			}
+			for key, status := range tt.want {
+				if got[key] != status {
+					t.Errorf("%s (%s) = %q, want %q", key.name, key.testType, got[key], status)
+				}
+			}
+		})
+	}
+}
+
+// runPatternSelects reports whether go test -run=pattern runs the test name,
+// matching each level of the name against the same level of an alternative
+func runPatternSelects(t *testing.T, pattern, name string) bool {
+	t.Helper()
+	nameLevels := strings.Split(name, "/")
+	for _, alternative := range splitRunPattern(pattern, '|') {
+		selected := true
+		for i, level := range splitRunPattern(alternative, '/') {
+			if i >= len(nameLevels) {
+				break
+			}
+			if !regexp.MustCompile(level).MatchString(nameLevels[i]) {
+				selected = false
+				break
+			}
+		}
+		if selected {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBuildRunPattern(t *testing.T) {
+	tests := []struct {
+		name        string
+		names       []string
+		want        string
+		selected    []string
+		notSelected []string
+	}{
+		{
+			name:  "no tests",
+			names: nil,
+			want:  "",
+		},
+		{
+			name:        "top-level tests",
+			names:       []string{"TestB", "TestA"},
+			want:        "^(TestA|TestB)$",
+			selected:    []string{"TestA", "TestB", "TestA/case"},
+			notSelected: []string{"TestAB", "TestC"},
+		},
+		{
+			name:        "escaping",
+			names:       []string{"TestParse/a+b", "TestParse/x|y", "TestParse/(1)"},
+			want:        `^TestParse$/^(\(1\)|a\+b|x\|y)$`,
+			selected:    []string{"TestParse/a+b", "TestParse/x|y", "TestParse/(1)"},
+			notSelected: []string{"TestParse/aab", "TestParse/x", "TestParse/1"},
+		},
+		{
+			name:        "subtests of different parents",
+			names:       []string{"TestA/x", "TestB/y"},
+			want:        "^TestA$/^(x)$|^TestB$/^(y)$",
+			selected:    []string{"TestA/x", "TestB/y"},
+			notSelected: []string{"TestA/y", "TestB/x"},
+		},
+		{
+			name:        "nested subtests",
+			names:       []string{"TestA/group/one", "TestA/group/two", "TestA/other/one"},
+			want:        "^TestA$/^group$/^(one|two)$|^TestA$/^other$/^(one)$",
+			selected:    []string{"TestA/group/one", "TestA/group/two", "TestA/other/one"},
+			notSelected: []string{"TestA/other/two", "TestA/group/three"},
+		},
+		{
+			name:        "mixed depths",
+			names:       []string{"TestA", "TestB/y"},
+			want:        "^(TestA)$|^TestB$/^(y)$",
+			selected:    []string{"TestA", "TestA/x", "TestB/y"},
+			notSelected: []string{"TestB/x"},
+		},
+		{
+			name:     "subtest of a listed parent",
+			names:    []string{"TestA", "TestA/x"},
+			want:     "^(TestA)$",
+			selected: []string{"TestA/x", "TestA/y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern := buildRunPattern(tt.names)
+			if pattern != tt.want {
+				t.Errorf("buildRunPattern(%q) = %q, want %q", tt.names, pattern, tt.want)
+			}
+			for _, name := range tt.selected {
+				if !runPatternSelects(t, pattern, name) {
+					t.Errorf("%q doesn't select %q", pattern, name)
+				}
+			}
+			for _, name := range tt.notSelected {
+				if runPatternSelects(t, pattern, name) {
+					t.Errorf("%q selects %q", pattern, name)
+				}
+			}
+		})
+	}
+}
+
+func TestRestrictRunPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		names   []string
+		want    string
+	}{
+		{
+			name:    "keeps the matching top-level tests",
+			pattern: "^(TestA|TestB|TestC)$",
+			names:   []string{"TestB", "TestD"},
+			want:    "^(TestB)$",
+		},
+		{
+			name:    "keeps deeper levels",
+			pattern: "^TestA$/^(x)$|^TestB$/^(y)$",
+			names:   []string{"TestB"},
+			want:    "^(TestB)$/^(y)$",
+		},
+		{
+			name:    "unanchored pattern",
+			pattern: "Dup",
+			names:   []string{"TestDup", "TestOther"},
+			want:    "^(TestDup)$",
+		},
+		{
+			name:    "no match",
+			pattern: "^(TestA)$",
+			names:   []string{"TestB"},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restrictRunPattern(tt.pattern, tt.names); got != tt.want {
+				t.Errorf("restrictRunPattern(%q, %q) = %q, want %q", tt.pattern, tt.names, got, tt.want)
+			}
+		})
+	}
+}
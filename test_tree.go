@@ -0,0 +1,202 @@
+package main
+
+import "strings"
+
+// testTreeNode is a test in the subtest tree of the details view
+// "TestFoo/case_1" is a child of "TestFoo"
+type testTreeNode struct {
+	test     TestResult
+	label    string // Name relative to the parent
+	depth    int
+	children []*testTreeNode
+
+	// Outcomes of every subtest below the node
+	passed, failed, flaky, skipped int
+}
+
+// hasFailures reports whether a subtest below the node failed or is flaky
+func (n *testTreeNode) hasFailures() bool {
+	return n.failed+n.flaky > 0
+}
+
+// subtests returns the number of finished subtests below the node
+func (n *testTreeNode) subtests() int {
+	return n.passed + n.failed + n.flaky + n.skipped
+}
+
+// buildTestTree arranges tests into trees by splitting their names on "/"
+// A subtest whose parent has no result of its own (e.g. after a rerun of the
+// subtest alone) gets a parent whose status is derived from its children
+// Siblings are ordered like a flat test group
+func buildTestTree(tests []TestResult) []*testTreeNode {
+	nodes := make(map[string]*testTreeNode, len(tests))
+	for _, test := range tests {
+		nodes[test.Name] = &testTreeNode{test: test}
+	}
+
+	var roots []*testTreeNode
+	var attach func(node *testTreeNode)
+	attach = func(node *testTreeNode) {
+		slash := strings.LastIndex(node.test.Name, "/")
+		if slash < 0 {
+			node.label = node.test.Name
+			roots = append(roots, node)
+			return
+		}
+		node.label = node.test.Name[slash+1:]
+		parentName := node.test.Name[:slash]
+		parent, exists := nodes[parentName]
+		if !exists {
+			parent = &testTreeNode{test: TestResult{Name: parentName, TestType: node.test.TestType}}
+			nodes[parentName] = parent
+			attach(parent)
+		}
+		parent.children = append(parent.children, node)
+	}
+	for _, test := range tests {
+		attach(nodes[test.Name])
+	}
+
+	for _, root := range roots {
+		countSubtests(root, 0)
+	}
+	return orderTestNodes(roots)
+}
+
+// countSubtests sets the depth and subtest outcomes of a node and everything below it
+// and orders its children
+func countSubtests(node *testTreeNode, depth int) {
+	node.depth = depth
+	for _, child := range node.children {
+		countSubtests(child, depth+1)
+		node.passed += child.passed
+		node.failed += child.failed
+		node.flaky += child.flaky
+		node.skipped += child.skipped
+		switch child.test.Status {
+		case "PASS":
+			node.passed++
+		case "FAIL":
+			node.failed++
+		case "FLAKY":
+			node.flaky++
+		case "SKIP":
+			node.skipped++
+		}
+	}
+	node.children = orderTestNodes(node.children)
+
+	// A parent made up for its subtests takes the worst status among them
+	if node.test.Status == "" {
+		switch {
+		case node.failed > 0:
+			node.test.Status = "FAIL"
+		case node.flaky > 0:
+			node.test.Status = "FLAKY"
+		case node.passed > 0:
+			node.test.Status = "PASS"
+		default:
+			node.test.Status = "SKIP"
+		}
+	}
+}
+
+// orderTestNodes orders sibling nodes like orderTestGroup orders tests
+// A parent that never finished is kept after them when it has subtests to show
+func orderTestNodes(nodes []*testTreeNode) []*testTreeNode {
+	byName := make(map[string]*testTreeNode, len(nodes))
+	tests := make([]TestResult, 0, len(nodes))
+	for _, node := range nodes {
+		byName[node.test.Name] = node
+		tests = append(tests, node.test)
+	}
+
+	ordered := make([]*testTreeNode, 0, len(nodes))
+	for _, test := range orderTestGroup(tests) {
+		ordered = append(ordered, byName[test.Name])
+		delete(byName, test.Name)
+	}
+	for _, node := range nodes {
+		if _, left := byName[node.test.Name]; left && len(node.children) > 0 {
+			ordered = append(ordered, node)
+		}
+	}
+	return ordered
+}
+
+// isTestExpanded reports whether the subtests of a node are shown
+// Parents with failing subtests start expanded and the rest collapsed, until
+// toggled in expanded
+func isTestExpanded(node *testTreeNode, expanded map[string]bool) bool {
+	if open, toggled := expanded[node.test.Name]; toggled {
+		return open
+	}
+	return node.hasFailures()
+}
+
+// visibleTestNodes returns the nodes of a tree as listed, skipping the
+// subtests of collapsed parents
+func visibleTestNodes(roots []*testTreeNode, expanded map[string]bool) []*testTreeNode {
+	var visible []*testTreeNode
+	var walk func(nodes []*testTreeNode)
+	walk = func(nodes []*testTreeNode) {
+		for _, node := range nodes {
+			visible = append(visible, node)
+			if len(node.children) > 0 && isTestExpanded(node, expanded) {
+				walk(node.children)
+			}
+		}
+	}
+	walk(roots)
+	return visible
+}
+
+// testTreeParents returns every test with subtests, parents before their subtests
+func testTreeParents(tests []TestResult) []*testTreeNode {
+	var parents []*testTreeNode
+	var walk func(nodes []*testTreeNode)
+	walk = func(nodes []*testTreeNode) {
+		for _, node := range nodes {
+			if len(node.children) > 0 {
+				parents = append(parents, node)
+				walk(node.children)
+			}
+		}
+	}
+	for _, group := range groupTestsByType(tests) {
+		walk(buildTestTree(group.tests))
+	}
+	return parents
+}
+
+// findTestNode returns the node of a test in the subtest tree, or nil if not found
+func findTestNode(tests []TestResult, name string) *testTreeNode {
+	var find func(nodes []*testTreeNode) *testTreeNode
+	find = func(nodes []*testTreeNode) *testTreeNode {
+		for _, node := range nodes {
+			if node.test.Name == name {
+				return node
+			}
+			if strings.HasPrefix(name, node.test.Name+"/") {
+				return find(node.children)
+			}
+		}
+		return nil
+	}
+	for _, group := range groupTestsByType(tests) {
+		if node := find(buildTestTree(group.tests)); node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// testAncestors returns the names of a test's parents, innermost first
+func testAncestors(name string) []string {
+	var ancestors []string
+	for slash := strings.LastIndex(name, "/"); slash >= 0; slash = strings.LastIndex(name, "/") {
+		name = name[:slash]
+		ancestors = append(ancestors, name)
+	}
+	return ancestors
+}
@@ -7,12 +7,14 @@ import (
 
 // TestEvent represents a single event from go test -json
 type TestEvent struct {
-	Time    time.Time
-	Action  string  // "start", "run", "pass", "fail", "skip", "output"
-	Package string
-	Test    string  // Present for test-specific events
-	Elapsed float64 // Duration in seconds
-	Output  string  // Present for "output" events
+	Time        time.Time
+	Action      string // "start", "run", "pause", "cont", "pass", "fail", "skip", "output", "build-output", "build-fail"
+	Package     string
+	Test        string  // Present for test-specific events
+	Elapsed     float64 // Duration in seconds
+	Output      string  // Present for "output" and "build-output" events
+	ImportPath  string  // Package being built, for "build-output" and "build-fail" events
+	FailedBuild string  // Set on a package's "fail" event when a build it needs failed
 }
 
 // formatDuration formats a duration with adaptive units for better precision display
@@ -44,18 +46,28 @@ func formatDuration(d time.Duration) string {
 // TestResult represents the result of a single test
 type TestResult struct {
 	Name     string
-	Status   string // "PASS", "FAIL", "SKIP"
+	Status   string // "PASS", "FAIL", "SKIP", "FLAKY" (passed and failed across repeated runs)
 	Duration time.Duration
-	Output   string // Detailed output for failed tests
-	TestType string // "unit", "integration", or "" for unknown
+	Output   string       // Detailed output for failed tests; output of the failing runs for repeated runs
+	TestType string       // "unit", the build tags the test needs (e.g. "integration", "e2e,slow"), or "" for unknown
+	Started  time.Time    // When the test started running (set while streaming)
+	Ended    time.Time    // When the test finished, zero until it does
+	Pauses   []TimeSpan   // Waits of a t.Parallel test for its parent's sequential part (pause to cont)
+	Hung     bool         // Flagged by the hang watchdog for running longer than the threshold
+	Runs     int          // Completed runs when the test was run repeatedly (Flaky Check), 0 otherwise
+	Failures int          // Failed runs out of Runs
+	Races    []DataRace   // Data races reported while the test ran (-race)
+	Panic    *PanicReport // Panic that ended the test, nil if it didn't panic
 }
 
 // FileCoverage represents coverage for a single file
 type FileCoverage struct {
-	FileName       string
-	CoveredLines   int
-	TotalLines     int
+	FileName        string
+	CoveredLines    int
+	TotalLines      int
 	CoveragePercent float64
+	Blocks          []coverageBlock // Blocks of the coverage profile, in profile order
+	CoveredBy       []string        // Test packages whose tests ran the file, with cross-package coverage
 }
 
 // FunctionCoverage represents coverage for a single function
@@ -72,23 +84,43 @@ type FunctionCoverage struct {
 // PackageTestResult represents test results for an entire package
 type PackageTestResult struct {
 	PackagePath       string
-	Status            string // "PASS", "FAIL", "RUNNING", "NOT_RUN"
+	Status            string // "PASS", "FAIL", "BUILD FAIL", "FLAKY", "RUNNING", "NOT_RUN"
 	Coverage          float64
 	TotalTests        int
 	PassedTests       int
 	FailedTests       int
 	SkippedTests      int
+	FlakyTests        int // Tests that both passed and failed across repeated runs
+	RunCount          int // Times each test was run (-count), 0 for a single run
 	Duration          time.Duration
+	Started           time.Time // When the test binary started ("start" event)
+	Ended             time.Time // When the package finished, zero until it does
 	Tests             []TestResult
 	FileCoverages     []FileCoverage     // Per-file coverage details
 	FunctionCoverages []FunctionCoverage // Per-function coverage details
 	FullOutput        string
+	Timeout           *TimeoutPanic     // Parsed go test -timeout panic, nil if the run didn't time out
+	ShuffleSeed       string            // Seed printed by go test -shuffle, empty if tests ran in order
+	Races             []DataRace        // Deduplicated data races of every test and the package itself
+	Panic             *PanicReport      // Panic that ended the test binary, nil if none
+	BuildErrors       []BuildDiagnostic // Compiler errors when the test binary failed to build
+	FailureKind       FailureKind       // Why the run failed, FailureNone unless it did
+	FailureDetail     string            // One line on the failure, e.g. the panic message
+	CPUProfile        string            // -cpuprofile written by a profiling run, empty otherwise
+	MemProfile        string            // -memprofile written by a profiling run, empty otherwise
+	ProfileBinary     string            // Test binary the profiles were taken from, for go tool pprof
+	ImportPath        string            // Import path of the package, resolved for cross-package coverage
+	CoverPkg          string            // -coverpkg patterns of the run, empty if it only measured the package
+	CoverageBlocks    coverageProfile   // Blocks of its own files and the others its -coverpkg run ran, nil otherwise
+	CoverageFrom      []string          // Test packages whose tests contributed to the coverage, with -coverpkg
 }
 
 // coverageBlock represents a coverage block from the coverage profile
 type coverageBlock struct {
 	startLine int
+	startCol  int
 	endLine   int
+	endCol    int
 	numStmt   int
-	count     int
+	count     int // Times the block ran (-covermode=count), or 0/1
 }
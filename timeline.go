@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TimeSpan is a period of wall-clock time
+type TimeSpan struct {
+	Start time.Time
+	End   time.Time // Zero while the period is still going on
+}
+
+// Duration returns the length of the span, 0 while it is open
+func (s TimeSpan) Duration() time.Duration {
+	if s.End.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// Bar cells of the timeline
+const (
+	timelineRunning = '█'
+	timelinePaused  = '┄'
+	timelineSetup   = '░'
+	timelineTests   = '─'
+)
+
+// setupStyle colors the part of a run spent outside tests
+var setupStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffff00"))
+
+// TimelineViewState holds the state for the test timeline view
+type TimelineViewState struct {
+	packageName string
+	selected    int
+	scroll      int
+	returnTo    appScreen // Screen to go back to on ESC
+}
+
+// PackageTimeline is a package's last run laid out over wall-clock time
+type PackageTimeline struct {
+	Span      TimeSpan     // From the test binary's start to the package's end
+	Tests     []TestResult // Tests with a start time inside the span, in the order they started
+	Left      int          // Tests left out: started outside the span, e.g. by a later rerun
+	FirstRun  time.Time    // When the first test started
+	LastEnd   time.Time    // When the last test finished
+	TestsBusy time.Duration
+}
+
+// testEnd returns when a test finished, or end if it never did
+func testEnd(test TestResult, end time.Time) time.Time {
+	if test.Ended.IsZero() {
+		return end
+	}
+	return test.Ended
+}
+
+// buildTimeline lays out the tests of a result over wall-clock time
+// Returns false if the run recorded no timing
+func buildTimeline(result *PackageTestResult) (PackageTimeline, bool) {
+	var timeline PackageTimeline
+	span := TimeSpan{Start: result.Started, End: result.Ended}
+
+	// Runs from before the start event was recorded only have test timestamps
+	for _, test := range result.Tests {
+		if test.Started.IsZero() {
+			continue
+		}
+		if span.Start.IsZero() || (result.Started.IsZero() && test.Started.Before(span.Start)) {
+			span.Start = test.Started
+		}
+		if result.Ended.IsZero() && test.Ended.After(span.End) {
+			span.End = test.Ended
+		}
+	}
+	if span.Start.IsZero() || !span.End.After(span.Start) {
+		return timeline, false
+	}
+	timeline.Span = span
+
+	for _, test := range result.Tests {
+		if test.Started.IsZero() || test.Started.Before(span.Start) || test.Started.After(span.End) {
+			timeline.Left++
+			continue
+		}
+		timeline.Tests = append(timeline.Tests, test)
+	}
+	sort.SliceStable(timeline.Tests, func(i, j int) bool {
+		return timeline.Tests[i].Started.Before(timeline.Tests[j].Started)
+	})
+
+	// Wall-clock time with at least one top-level test running
+	var busy []TimeSpan
+	for _, test := range timeline.Tests {
+		end := testEnd(test, span.End)
+		if timeline.FirstRun.IsZero() || test.Started.Before(timeline.FirstRun) {
+			timeline.FirstRun = test.Started
+		}
+		if end.After(timeline.LastEnd) {
+			timeline.LastEnd = end
+		}
+		if strings.Contains(test.Name, "/") {
+			continue
+		}
+		if n := len(busy); n > 0 && !test.Started.After(busy[n-1].End) {
+			if end.After(busy[n-1].End) {
+				busy[n-1].End = end
+			}
+			continue
+		}
+		busy = append(busy, TimeSpan{Start: test.Started, End: end})
+	}
+	for _, period := range busy {
+		timeline.TestsBusy += period.Duration()
+	}
+	if len(timeline.Tests) == 0 {
+		timeline.FirstRun, timeline.LastEnd = span.End, span.End
+	}
+	return timeline, true
+}
+
+// FormatTimeline renders a package's last run as a Gantt chart: one bar per test
+// over wall-clock time, with the waits of parallel tests and the time the run
+// spent outside tests, which the details view counts as setup
+// Returns the content and the line of the selected test
+func FormatTimeline(result *PackageTestResult, theme Theme, state TimelineViewState, width int) (string, int) {
+	var output strings.Builder
+
+	normalStyle := lipgloss.NewStyle().Foreground(theme.NormalFg)
+	metricStyle := lipgloss.NewStyle().Foreground(theme.MenuActiveFg)
+	helpStyle := lipgloss.NewStyle().Foreground(theme.HelpColor)
+	separatorStyle := lipgloss.NewStyle().Foreground(theme.TreeSymbolColor)
+	passStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00"))
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	selectedStyle := lipgloss.NewStyle().Foreground(theme.SelectedFg).Background(theme.SelectedBg)
+
+	output.WriteString(normalStyle.Render("Timeline: "+result.PackagePath) + "\n")
+	output.WriteString(separatorStyle.Render(strings.Repeat("─", Max(width, 20))) + "\n")
+
+	timeline, ok := buildTimeline(result)
+	if !ok {
+		output.WriteString(normalStyle.Render("The run recorded no test timing.") + "\n")
+		return output.String(), 0
+	}
+	if result.Duration == 0 {
+		output.WriteString(helpStyle.Render("Results came from the test cache: the times are those of the replay, not of the original run.") + "\n\n")
+	}
+
+	// Where the time went: the details view's "setup" is the elapsed time minus
+	// the sum of test durations, which counts subtests within their parents and
+	// parallel tests once each
+	total := timeline.Span.Duration()
+	var testTimeSum time.Duration
+	for _, test := range result.Tests {
+		testTimeSum += test.Duration
+	}
+	breakdown := []struct {
+		label string
+		value time.Duration
+		note  string
+	}{
+		{"Package run", total, "test binary start to exit"},
+		{"  before first test", timeline.FirstRun.Sub(timeline.Span.Start), "package init, TestMain before m.Run"},
+		{"  tests running", timeline.TestsBusy, "wall-clock, parallel tests overlapping"},
+		{"  between tests", timeline.LastEnd.Sub(timeline.FirstRun) - timeline.TestsBusy, ""},
+		{"  after last test", timeline.Span.End.Sub(timeline.LastEnd), "TestMain after m.Run, coverage and profiles"},
+	}
+	for _, row := range breakdown {
+		style := metricStyle
+		if strings.Contains(row.label, "before") || strings.Contains(row.label, "after") {
+			style = setupStyle
+		}
+		line := normalStyle.Render(fmt.Sprintf("%-20s", row.label)) + style.Render(fmt.Sprintf("%9s", formatDuration(max(row.value, 0))))
+		if row.note != "" {
+			line += helpStyle.Render("  " + row.note)
+		}
+		output.WriteString(line + "\n")
+	}
+	output.WriteString(helpStyle.Render(fmt.Sprintf("The details view's setup (%s) is the elapsed time minus the %s sum of test durations,",
+		formatDuration(max(result.Duration-testTimeSum, 0)), formatDuration(testTimeSum))) + "\n")
+	output.WriteString(helpStyle.Render("which counts subtests within their parents and overlapping parallel tests one by one.") + "\n")
+	if timeline.Left > 0 {
+		output.WriteString(helpStyle.Render(fmt.Sprintf("%d test(s) from a later rerun are not shown.", timeline.Left)) + "\n")
+	}
+	output.WriteString("\n")
+
+	// The selected test in words, above the chart so it stays in view
+	selected := Clamp(state.selected, 0, Max(len(timeline.Tests)-1, 0))
+	if len(timeline.Tests) > 0 {
+		test := timeline.Tests[selected]
+		end := testEnd(test, timeline.Span.End)
+		var paused time.Duration
+		for _, pause := range test.Pauses {
+			if pause.End.IsZero() {
+				pause.End = end
+			}
+			paused += pause.Duration()
+		}
+		output.WriteString(styledStatus(test.Status, theme) + " " + normalStyle.Render(test.Name) + "\n")
+		detail := fmt.Sprintf("  +%s to +%s, running %s", formatDuration(test.Started.Sub(timeline.Span.Start)),
+			formatDuration(end.Sub(timeline.Span.Start)), formatDuration(end.Sub(test.Started)-paused))
+		if paused > 0 {
+			detail += fmt.Sprintf(", paused %s waiting to run in parallel (t.Parallel)", formatDuration(paused))
+		}
+		output.WriteString(metricStyle.Render(detail) + "\n\n")
+	} else {
+		output.WriteString(normalStyle.Render("No tests ran.") + "\n\n")
+	}
+
+	// Chart: label, bar over the package span, duration
+	labelWidth := Clamp(width/3, 16, 40)
+	barWidth := Max(width-labelWidth-14, 10)
+	column := func(t time.Time) int {
+		return Clamp(int(float64(t.Sub(timeline.Span.Start))/float64(total)*float64(barWidth)), 0, barWidth-1)
+	}
+
+	axis := []rune(strings.Repeat(" ", barWidth))
+	for i, label := range []string{"0", formatDuration(total / 2), formatDuration(total)} {
+		start := []int{0, barWidth/2 - len(label)/2, barWidth - len(label)}[i]
+		for j, r := range label {
+			if start+j >= 0 && start+j < barWidth {
+				axis[start+j] = r
+			}
+		}
+	}
+	output.WriteString(strings.Repeat(" ", labelWidth+3) + helpStyle.Render(string(axis)) + "\n")
+
+	// The package row marks the time spent outside tests
+	cells := []rune(strings.Repeat(string(timelineTests), barWidth))
+	for c := 0; c < column(timeline.FirstRun); c++ {
+		cells[c] = timelineSetup
+	}
+	for c := column(timeline.LastEnd) + 1; c < barWidth; c++ {
+		cells[c] = timelineSetup
+	}
+	output.WriteString("  " + normalStyle.Render(fmt.Sprintf("%-*s", labelWidth, "(package)")) + " " +
+		renderTimelineCells(cells, map[rune]lipgloss.Style{timelineSetup: setupStyle, timelineTests: separatorStyle}) +
+		metricStyle.Render(fmt.Sprintf(" %9s", formatDuration(total))) + "\n")
+
+	cursorLine := 0
+	for i, test := range timeline.Tests {
+		depth := strings.Count(test.Name, "/")
+		label := strings.Repeat("  ", depth) + test.Name[strings.LastIndex(test.Name, "/")+1:]
+		if len([]rune(label)) > labelWidth {
+			label = string([]rune(label)[:labelWidth-1]) + "…"
+		}
+
+		end := testEnd(test, timeline.Span.End)
+		cells := []rune(strings.Repeat(" ", barWidth))
+		for c := column(test.Started); c <= column(end); c++ {
+			cells[c] = timelineRunning
+		}
+		for _, pause := range test.Pauses {
+			if pause.End.IsZero() {
+				pause.End = end
+			}
+			for c := column(pause.Start); c <= column(pause.End); c++ {
+				cells[c] = timelinePaused
+			}
+		}
+
+		barStyle := passStyle
+		switch test.Status {
+		case "FAIL":
+			barStyle = failStyle
+		case "FLAKY":
+			barStyle = flakyStyle
+		case "SKIP", "RUNNING":
+			barStyle = normalStyle
+		}
+		bar := renderTimelineCells(cells, map[rune]lipgloss.Style{timelineRunning: barStyle, timelinePaused: helpStyle})
+		labelText := fmt.Sprintf("%-*s", labelWidth, label)
+		if i == selected {
+			cursorLine = strings.Count(output.String(), "\n")
+			output.WriteString(selectedStyle.Render(testCursorMarker+labelText) + " " + bar +
+				metricStyle.Render(fmt.Sprintf(" %9s", formatDuration(test.Duration))) + "\n")
+		} else {
+			output.WriteString("  " + normalStyle.Render(labelText) + " " + bar +
+				metricStyle.Render(fmt.Sprintf(" %9s", formatDuration(test.Duration))) + "\n")
+		}
+	}
+
+	output.WriteString("\n" + passStyle.Render(string(timelineRunning)) + helpStyle.Render(" running  ") +
+		helpStyle.Render(string(timelinePaused)+" paused (t.Parallel)  ") +
+		setupStyle.Render(string(timelineSetup)) + helpStyle.Render(" outside tests") + "\n")
+	return output.String(), cursorLine
+}
+
+// renderTimelineCells renders a bar, styling each run of equal cells at once
+func renderTimelineCells(cells []rune, styles map[rune]lipgloss.Style) string {
+	var bar strings.Builder
+	for start := 0; start < len(cells); {
+		end := start
+		for end < len(cells) && cells[end] == cells[start] {
+			end++
+		}
+		run := string(cells[start:end])
+		if style, ok := styles[cells[start]]; ok {
+			run = style.Render(run)
+		}
+		bar.WriteString(run)
+		start = end
+	}
+	return bar.String()
+}